@@ -9,9 +9,12 @@ import (
 	"github.com/mohamedirfanam/cynterpreter/lexer/token"
 	"github.com/mohamedirfanam/cynterpreter/parser"
 	"github.com/mohamedirfanam/cynterpreter/parser/ast"
+	"github.com/mohamedirfanam/cynterpreter/preprocessor"
 )
 
-func HandleFile(filName string) {
+// HandleFile preprocesses, parses and runs the program in filName, the
+// files it includes are searched next to it and then in includePaths.
+func HandleFile(filName string, includePaths ...string) {
 
 	data, err := os.ReadFile(filName)
 	if err != nil {
@@ -19,7 +22,16 @@ func HandleFile(filName string) {
 		return
 	}
 
-	var p = parser.New(string(data))
+	pp := preprocessor.New(includePaths...)
+	src := pp.Process(filName, string(data))
+	if len(pp.Errors()) != 0 {
+		for _, err := range pp.Errors() {
+			fmt.Printf("Preprocessor Error: %s\n", err.Error())
+		}
+		return
+	}
+
+	var p = parser.NewFile(filName, src)
 
 	program := p.ParseProgram()
 
@@ -30,17 +42,23 @@ func HandleFile(filName string) {
 		return
 	}
 	env := obj.NewEnv()
-	eval.Eval(program, env)
+	// global declarations are evaluated once, in order, before main runs
+	result := eval.Eval(program, env)
+	if errorObj, ok := result.(*obj.ErrorObject); ok {
+		fmt.Print(errorObj.String())
+		return
+	}
 
 	_, ok := env.GetVar("main")
 	if !ok {
 		fmt.Printf("Program error: No main function found")
+		return
 	}
 
-	result := eval.Eval(getMainCall(), env)
+	result = eval.Eval(getMainCall(), env)
 	errorObj, ok := result.(*obj.ErrorObject)
 	if ok {
-		fmt.Print(errorObj.Error.Error())
+		fmt.Print(errorObj.String())
 	}
 }
 
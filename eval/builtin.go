@@ -3,12 +3,16 @@ package eval
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/mohamedirfanam/cynterpreter/eval/obj"
 	"github.com/mohamedirfanam/cynterpreter/parser/ast"
 )
 
+// Stdout is where the output of print and printf goes.
+var Stdout io.Writer = os.Stdout
+
 type BuildInFunc func(args ...obj.Object) obj.Object
 
 var BuiltInFuncMap = map[string]BuildInFunc{
@@ -25,6 +29,9 @@ func ApplyBuiltInFunc(funcName string, args []ast.Expression, env *obj.Environme
 	var argsObjs []obj.Object
 	for _, arg := range args {
 		argObj := Eval(arg, env)
+		if argObj.Type() == obj.ERROR_OBJ {
+			return argObj, true
+		}
 		argsObjs = append(argsObjs, argObj)
 	}
 	result := buildInfunc(argsObjs...)
@@ -36,7 +43,7 @@ func print(args ...obj.Object) obj.Object {
 	for _, arg := range args {
 		vals = append(vals, obj.ExtractVal(arg))
 	}
-	fmt.Print(vals...)
+	fmt.Fprint(Stdout, vals...)
 	return obj.NULL
 }
 
@@ -49,7 +56,7 @@ func printf(args ...obj.Object) obj.Object {
 	for _, arg := range args[1:] {
 		vals = append(vals, obj.ExtractVal(arg))
 	}
-	fmt.Printf(format.Value, vals...)
+	fmt.Fprintf(Stdout, format.Value, vals...)
 	return obj.NULL
 }
 
@@ -57,7 +64,7 @@ func input(args ...obj.Object) obj.Object {
 	if len(args) > 0 {
 		prompt, ok := args[0].(*obj.StringObject)
 		if ok {
-			fmt.Print(prompt)
+			fmt.Fprint(Stdout, prompt)
 		}
 	}
 
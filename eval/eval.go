@@ -1,22 +1,46 @@
 package eval
 
 import (
+	"fmt"
+
 	"github.com/mohamedirfanam/cynterpreter/eval/obj"
 	"github.com/mohamedirfanam/cynterpreter/parser/ast"
 )
 
+// Eval evaluates node in env, an error raised while evaluating it is
+// located at the innermost node it came from.
 func Eval(node ast.Node, env *obj.Environment) obj.Object {
+	result := eval(node, env)
+	if errorObj, ok := result.(*obj.ErrorObject); ok && !errorObj.Pos.IsValid() {
+		errorObj.Pos = node.Pos()
+	}
+	return result
+}
+
+func eval(node ast.Node, env *obj.Environment) obj.Object {
 	switch node := node.(type) {
 	case *ast.Program:
 		return evalProgram(node.Statements, env)
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
+	case *ast.Block:
+		return evalScopedBlock(node, env)
 	case *ast.IfStatement:
 		return evalIfStatement(node, env)
 	case *ast.WhileStatement:
 		return evalWhileLoop(node, env)
+	case *ast.DoWhileStatement:
+		return evalDoWhileLoop(node, env)
 	case *ast.ForStatement:
 		return evalForLoop(node, env)
+	case *ast.SwitchStatement:
+		return evalSwitchStatement(node, env)
+	case *ast.BreakStatement:
+		return obj.BREAK
+	case *ast.ContinueStatement:
+		return obj.CONTINUE
+	case *ast.StructDeclaration:
+		return evalStructDeclaration(node, env)
 	case *ast.DeclarationStatement:
 		return evalDeclarationStatement(node, env)
 	case *ast.AssignmentStatement:
@@ -29,8 +53,14 @@ func Eval(node ast.Node, env *obj.Environment) obj.Object {
 		return evalIdentifierExpression(node, env)
 	case *ast.ArrayExpression:
 		return evalArrayExpression(node, env)
+	case *ast.DereferenceExpression:
+		return evalDereferenceExpression(node, env)
+	case *ast.MemberExpression:
+		return evalMemberExpression(node, env)
+	case *ast.InitializerList:
+		return obj.NewError(fmt.Errorf("syntax error: initializer list %s is only valid in a declaration", node))
 	case *ast.IntegerLiteral:
-		return &obj.IntegerObject{Value: node.Value}
+		return evalIntegerLiteral(node, false)
 	case *ast.BoolLiteral:
 		if node.Value {
 			return obj.TRUE
@@ -47,6 +77,14 @@ func Eval(node ast.Node, env *obj.Environment) obj.Object {
 		return evalInfixExpression(node, env)
 	case *ast.PrefixExpression:
 		return evalPrefixExpression(node, env)
+	case *ast.AssignmentExpression:
+		return evalAssignment(node.Target, node.Value, env)
+	case *ast.CompoundAssignmentExpression:
+		return evalCompoundAssignment(node.Operator(), node.Target, node.Value, env)
+	case *ast.ConditionalExpression:
+		return evalConditionalExpression(node, env)
+	case *ast.PostfixExpression:
+		return evalIncDecExpression(node.Token, node.Exp, false, env)
 
 	}
 	return obj.NULL
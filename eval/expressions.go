@@ -2,6 +2,7 @@ package eval
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/mohamedirfanam/cynterpreter/eval/obj"
 	"github.com/mohamedirfanam/cynterpreter/lexer/token"
@@ -17,34 +18,234 @@ func evalIdentifierExpression(ident *ast.IdentifierExpression, env *obj.Environm
 }
 
 func evalArrayExpression(arr *ast.ArrayExpression, env *obj.Environment) obj.Object {
-	expObj := Eval(arr.Index, env)
-	expInt, ok := expObj.(*obj.IntegerObject)
-	if !ok {
-		return obj.NewError(fmt.Errorf("invalid index type, expected an integer, got %s", expObj.Type()))
+	container, index, errObj := evalArrayOperand(arr, env)
+	if errObj != nil {
+		return errObj
 	}
-	val, err := env.GetIndexVar(arr.Identifer.Value, int(expInt.Value))
+	if errObj := checkIndex(arr, container, index, false); errObj != nil {
+		return errObj
+	}
+	val, err := env.Index(container, index)
 	if err == nil {
 		return val
 	}
 	return obj.NewError(err)
 }
 
+// evalArrayOperand evaluates the array, string or pointer arr subscripts and
+// the index, for m[i][j] the array subscripted is the row m[i].
+func evalArrayOperand(arr *ast.ArrayExpression, env *obj.Environment) (obj.Object, int, obj.Object) {
+	container := Eval(arr.Left, env)
+	if container.Type() == obj.ERROR_OBJ {
+		return nil, 0, container
+	}
+	switch container.Type() {
+	case obj.ARRAY_OBJ, obj.STRING_OBJ, obj.POINTER_OBJ:
+	default:
+		return nil, 0, obj.NewError(fmt.Errorf("type error: subscripted value %s is not an array, string or pointer, got %s", arr.Left, container.Type()))
+	}
+	expObj := Eval(arr.Index, env)
+	if expObj.Type() == obj.ERROR_OBJ {
+		return nil, 0, expObj
+	}
+	expInt, ok := expObj.(*obj.IntegerObject)
+	if !ok {
+		return nil, 0, obj.NewError(fmt.Errorf("invalid index type, expected an integer, got %s", expObj.Type()))
+	}
+	return container, int(expInt.Value), nil
+}
+
 func evalPrefixExpression(expr *ast.PrefixExpression, env *obj.Environment) obj.Object {
+	switch expr.Token.TokenType {
+	case token.AMP:
+		return evalAddressOf(expr.Exp, env)
+	case token.INCR, token.DECR:
+		return evalIncDecExpression(expr.Token, expr.Exp, true, env)
+	}
+	if lit, ok := expr.Exp.(*ast.IntegerLiteral); ok && expr.Token.TokenType == token.MINUS {
+		return evalIntegerLiteral(lit, true)
+	}
 	val := Eval(expr.Exp, env)
+	if val.Type() == obj.ERROR_OBJ {
+		return val
+	}
 	switch expr.Token.TokenType {
 	case token.MINUS:
 		return evalPrefixMinusOp(val)
 	case token.NOT:
 		return evalPrefixNotOp(val)
+	case token.TILDE:
+		return evalPrefixComplementOp(val)
 	default:
 		return obj.NewError(fmt.Errorf("operator error: Not a valid operator, got %s", expr.Token.TokenType))
 	}
 }
 
+// evalIncDecExpression applies ++ or -- to an lvalue, the operand is
+// resolved once so that side effects inside it happen once.
+// The prefix forms give the updated value, the postfix forms the old one.
+func evalIncDecExpression(op token.Token, operand ast.Expression, prefix bool, env *obj.Environment) obj.Object {
+	lv, errObj := evalLvalue(op, operand, env)
+	if errObj != nil {
+		return errObj
+	}
+	oldVal, err := lv.load(env)
+	if err != nil {
+		return obj.NewError(err)
+	}
+	var delta int64 = 1
+	if op.TokenType == token.DECR {
+		delta = -1
+	}
+	var newVal obj.Object
+	switch val := oldVal.(type) {
+	case *obj.IntegerObject:
+		newVal = intObject(val.Value + delta)
+	case *obj.CharObject:
+		newVal = &obj.CharObject{Value: val.Value + byte(delta)}
+	case *obj.FloatObject:
+		newVal = &obj.FloatObject{Value: val.Value + float64(delta)}
+	case *obj.PointerObject:
+		newVal = val.Offset(delta)
+	default:
+		return obj.NewError(fmt.Errorf("type error: Invalid operand type for %s operator, expected number or pointer but got %s", op.Lexeme, typeString(oldVal)))
+	}
+	if err := lv.store(env, newVal); err != nil {
+		return obj.NewError(err)
+	}
+	if prefix {
+		return newVal
+	}
+	return oldVal
+}
+
+// lvalue is a location an operator reads and then updates in place. Most
+// lvalues have an address, a char of a string is reached through the string.
+type lvalue struct {
+	ptr   *obj.PointerObject
+	str   *obj.StringObject
+	index int
+}
+
+func (lv *lvalue) load(env *obj.Environment) (obj.Object, error) {
+	if lv.str != nil {
+		return env.Index(lv.str, lv.index)
+	}
+	return env.Memory().Deref(lv.ptr)
+}
+
+func (lv *lvalue) store(env *obj.Environment, val obj.Object) error {
+	if lv.str != nil {
+		return env.SetIndex(lv.str, lv.index, val)
+	}
+	return env.Memory().Assign(lv.ptr, val)
+}
+
+// evalLvalue resolves the operand of op to the location it updates, once so
+// that side effects inside it happen once. Arrays are rejected since their
+// address is the one of their first element.
+func evalLvalue(op token.Token, operand ast.Expression, env *obj.Environment) (*lvalue, obj.Object) {
+	switch operand := operand.(type) {
+	case *ast.IdentifierExpression:
+		if val, ok := env.GetVar(operand.Value); ok && val.Type() == obj.ARRAY_OBJ {
+			return nil, obj.NewError(fmt.Errorf("type error: array %s is not assignable", operand))
+		}
+	case *ast.MemberExpression:
+		st, index, errObj := evalMemberOperand(operand, env)
+		if errObj != nil {
+			return nil, errObj
+		}
+		if st.Vals[index].Type() == obj.ARRAY_OBJ {
+			return nil, obj.NewError(fmt.Errorf("type error: array %s is not assignable", operand))
+		}
+		return &lvalue{ptr: obj.GetPointerObject(st.Addr+st.Offset(index), st.Vals[index])}, nil
+	case *ast.ArrayExpression:
+		container, index, errObj := evalArrayOperand(operand, env)
+		if errObj != nil {
+			return nil, errObj
+		}
+		if errObj := checkIndex(operand, container, index, false); errObj != nil {
+			return nil, errObj
+		}
+		if str, ok := container.(*obj.StringObject); ok {
+			return &lvalue{str: str, index: index}, nil
+		}
+		if elem, err := env.Index(container, index); err == nil && elem.Type() == obj.ARRAY_OBJ {
+			return nil, obj.NewError(fmt.Errorf("type error: array %s is not assignable", operand))
+		}
+		ptr, errObj := evalElementAddress(operand, container, index)
+		if errObj != nil {
+			return nil, errObj
+		}
+		return &lvalue{ptr: ptr}, nil
+	case ast.IdentifierNode:
+	default:
+		return nil, obj.NewError(fmt.Errorf("operator error: operand of %s is not an lvalue, got %s", op.Lexeme, operand))
+	}
+	addr := evalAddressOf(operand, env)
+	ptr, ok := addr.(*obj.PointerObject)
+	if !ok {
+		return nil, addr
+	}
+	if ptr.IsRow() {
+		return nil, obj.NewError(fmt.Errorf("type error: array %s is not assignable", operand))
+	}
+	return &lvalue{ptr: ptr}, nil
+}
+
+// functionDefinition returns the definition of the function fn declares. A
+// prototype inside a block refers to the function of the same name at file
+// scope, which is looked up at the call since it may be defined after the
+// block.
+func functionDefinition(name string, fn *obj.FunctionObject, env *obj.Environment) (*obj.FunctionObject, error) {
+	if fn.Block != nil {
+		return fn, nil
+	}
+	if global, ok := env.GetGlobal(name); ok {
+		if def, ok := global.(*obj.FunctionObject); ok && def.Block != nil {
+			if err := checkFunctionSignature(name, fn, def); err != nil {
+				return nil, err
+			}
+			return def, nil
+		}
+	}
+	return nil, fmt.Errorf("link error: undefined reference to function %s, it is declared but never defined", name)
+}
+
+// evalIntegerLiteral converts lit, negated when it follows a minus, to an
+// int, the one integer type of the interpreter. An unsigned constant such as
+// the mask 0xFFFFFFFF wraps around like in any conversion to int, while a
+// long one must hold an int value, so -2147483648 is INT_MIN but 3000000000
+// is an error.
+func evalIntegerLiteral(lit *ast.IntegerLiteral, negate bool) obj.Object {
+	val := lit.Value
+	if negate && !lit.Unsigned {
+		val = -val
+	}
+	if lit.Long && (lit.Unsigned && uint64(lit.Value) > math.MaxUint32 || !lit.Unsigned && val != int64(int32(val))) {
+		kind := "long"
+		if lit.Unsigned {
+			kind = "unsigned long"
+		}
+		return obj.NewError(fmt.Errorf("type error: integer constant %s of type %s does not fit in an int", lit, kind))
+	}
+	result := int32(lit.Value)
+	if negate {
+		result = -result
+	}
+	return &obj.IntegerObject{Value: int64(result)}
+}
+
+// intObject returns v wrapped to the 32 bits of an int, signed overflow
+// wraps around the way it does on the machines C usually runs on.
+func intObject(v int64) *obj.IntegerObject {
+	return &obj.IntegerObject{Value: int64(int32(v))}
+}
+
 func evalPrefixMinusOp(val obj.Object) obj.Object {
 	switch val := val.(type) {
 	case *obj.IntegerObject:
-		return &obj.IntegerObject{Value: -1 * val.Value}
+		return intObject(-val.Value)
 	case *obj.FloatObject:
 		return &obj.FloatObject{Value: -1 * val.Value}
 	default:
@@ -52,6 +253,14 @@ func evalPrefixMinusOp(val obj.Object) obj.Object {
 	}
 }
 
+func evalPrefixComplementOp(val obj.Object) obj.Object {
+	intVal, ok := bitwiseOperand(val)
+	if !ok {
+		return obj.NewError(fmt.Errorf("type error: Invalid operand type for bitwise NOT operator, expected integer but got %s", val.Type()))
+	}
+	return &obj.IntegerObject{Value: int64(^int32(intVal))}
+}
+
 func evalPrefixNotOp(val obj.Object) obj.Object {
 	switch val {
 	case obj.TRUE:
@@ -61,6 +270,9 @@ func evalPrefixNotOp(val obj.Object) obj.Object {
 	case obj.NULL:
 		return obj.FALSE
 	default:
+		if ptr, ok := val.(*obj.PointerObject); ok {
+			return obj.GetBoolean(ptr.IsNull())
+		}
 		intVal, ok := val.(*obj.IntegerObject)
 		if ok {
 			if intVal.Value == 0 {
@@ -82,9 +294,35 @@ func evalPrefixNotOp(val obj.Object) obj.Object {
 }
 
 func evalInfixExpression(expr *ast.InfixExpression, env *obj.Environment) obj.Object {
-	rightVal := Eval(expr.RightExp, env)
-	leftVal := Eval(expr.LeftExp, env)
 	switch expr.Token.TokenType {
+	case token.AND, token.OR:
+		return evalLogicalExpression(expr, env)
+	case token.COMMA:
+		// the left operand is evaluated for its side effects only
+		if leftVal := Eval(expr.LeftExp, env); leftVal.Type() == obj.ERROR_OBJ {
+			return leftVal
+		}
+		return Eval(expr.RightExp, env)
+	}
+	// operands are evaluated left to right
+	leftVal := Eval(expr.LeftExp, env)
+	if leftVal.Type() == obj.ERROR_OBJ {
+		return leftVal
+	}
+	rightVal := Eval(expr.RightExp, env)
+	if rightVal.Type() == obj.ERROR_OBJ {
+		return rightVal
+	}
+	return evalBinaryOperator(expr.Token, leftVal, rightVal)
+}
+
+// evalBinaryOperator applies op to operands already evaluated, it is shared by
+// infix expressions and compound assignments.
+func evalBinaryOperator(op token.Token, leftVal obj.Object, rightVal obj.Object) obj.Object {
+	if isPointerOperand(leftVal) || isPointerOperand(rightVal) {
+		return evalPointerInfixExpression(op.TokenType, leftVal, rightVal)
+	}
+	switch op.TokenType {
 	case token.PLUS:
 		return evalInfixPlusOp(leftVal, rightVal)
 	case token.MINUS:
@@ -107,12 +345,10 @@ func evalInfixExpression(expr *ast.InfixExpression, env *obj.Environment) obj.Ob
 		return evalInfixEQOp(leftVal, rightVal)
 	case token.NE:
 		return evalInfixNEOp(leftVal, rightVal)
-	case token.AND:
-		return evalInfixANDOp(leftVal, rightVal)
-	case token.OR:
-		return evalInfixOROp(leftVal, rightVal)
+	case token.AMP, token.PIPE, token.XOR, token.LSHIFT, token.RSHIFT:
+		return evalInfixBitwiseOp(op, leftVal, rightVal)
 	default:
-		return obj.NewError(fmt.Errorf("operator error: Unsupported infix operator '%s'", expr.Token.TokenType))
+		return obj.NewError(fmt.Errorf("operator error: Unsupported infix operator '%s'", op.TokenType))
 	}
 }
 
@@ -129,7 +365,7 @@ func evalInfixPlusOp(leftVal obj.Object, rightVal obj.Object) obj.Object {
 		if isFloat(leftVal) || isFloat(rightVal) {
 			return &obj.FloatObject{Value: lNum + rNum}
 		}
-		return &obj.IntegerObject{Value: int64(lNum) + int64(rNum)}
+		return intObject(int64(lNum) + int64(rNum))
 	}
 
 	return obj.NewError(fmt.Errorf("type error: Invalid operand types for addition operator, expected number+number or string+string but got %s + %s", leftVal.Type(), rightVal.Type()))
@@ -142,7 +378,7 @@ func evalInfixMinusOp(leftVal obj.Object, rightVal obj.Object) obj.Object {
 		if isFloat(leftVal) || isFloat(rightVal) {
 			return &obj.FloatObject{Value: lNum - rNum}
 		}
-		return &obj.IntegerObject{Value: int64(lNum) - int64(rNum)}
+		return intObject(int64(lNum) - int64(rNum))
 	}
 
 	return obj.NewError(fmt.Errorf("type error: Invalid operand types for subtraction operator, expected number-number but got %s - %s", leftVal.Type(), rightVal.Type()))
@@ -155,7 +391,7 @@ func evalInfixMultOp(leftVal obj.Object, rightVal obj.Object) obj.Object {
 		if isFloat(leftVal) || isFloat(rightVal) {
 			return &obj.FloatObject{Value: lNum * rNum}
 		}
-		return &obj.IntegerObject{Value: int64(lNum) * int64(rNum)}
+		return intObject(int64(lNum) * int64(rNum))
 	}
 
 	return obj.NewError(fmt.Errorf("type error: Invalid operand types for product operator, expected number*number but got %s * %s", leftVal.Type(), rightVal.Type()))
@@ -171,7 +407,7 @@ func evalInfixDevideOp(leftVal obj.Object, rightVal obj.Object) obj.Object {
 		if isFloat(leftVal) || isFloat(rightVal) {
 			return &obj.FloatObject{Value: lNum / rNum}
 		}
-		return &obj.IntegerObject{Value: int64(lNum) / int64(rNum)}
+		return intObject(int64(lNum) / int64(rNum))
 	}
 
 	return obj.NewError(fmt.Errorf("type error: Invalid operand types for devide operator, expected number/number but got %s / %s", leftVal.Type(), rightVal.Type()))
@@ -187,7 +423,7 @@ func evalInfixModOp(leftVal obj.Object, rightVal obj.Object) obj.Object {
 	if rVal.Value == 0 {
 		return obj.NewError(fmt.Errorf("runtime error: devide by zero "))
 	}
-	return &obj.IntegerObject{Value: int64(lVal.Value) % int64(rVal.Value)}
+	return intObject(lVal.Value % rVal.Value)
 
 }
 
@@ -297,12 +533,165 @@ func evalInfixGEOp(leftVal obj.Object, rightVal obj.Object) obj.Object {
 	return obj.NewError(fmt.Errorf("type error: Invalid operand types for Greater Than or Equal operator, expected number>=number but got %s >= %s", leftVal.Type(), rightVal.Type()))
 }
 
-func evalInfixANDOp(leftVal obj.Object, rightVal obj.Object) obj.Object {
-	return obj.GetBoolean(IsTrue(leftVal) && IsTrue(rightVal))
+// evalInfixBitwiseOp applies the bitwise and shift operators, which take
+// integer operands only, chars and bools being promoted to int. They work on
+// the 32 bits of an int, so 1 << 31 overflows to INT_MIN and shifting by 32
+// or more is an error.
+func evalInfixBitwiseOp(op token.Token, leftVal obj.Object, rightVal obj.Object) obj.Object {
+	lVal, lIsInt := bitwiseOperand(leftVal)
+	rVal, rIsInt := bitwiseOperand(rightVal)
+	if !lIsInt || !rIsInt {
+		return obj.NewError(fmt.Errorf("type error: Invalid operand types for bitwise operator '%s', expected integer %s integer but got %s %s %s", op.Lexeme, op.Lexeme, leftVal.Type(), op.Lexeme, rightVal.Type()))
+	}
+	left, right := int32(lVal), int32(rVal)
+	switch op.TokenType {
+	case token.AMP:
+		return &obj.IntegerObject{Value: int64(left & right)}
+	case token.PIPE:
+		return &obj.IntegerObject{Value: int64(left | right)}
+	case token.XOR:
+		return &obj.IntegerObject{Value: int64(left ^ right)}
+	}
+	if rVal < 0 || rVal >= 32 {
+		return obj.NewError(fmt.Errorf("runtime error: shift count %d out of range for int", rVal))
+	}
+	if op.TokenType == token.LSHIFT {
+		return &obj.IntegerObject{Value: int64(left << rVal)}
+	}
+	return &obj.IntegerObject{Value: int64(left >> rVal)}
+}
+
+func bitwiseOperand(val obj.Object) (int64, bool) {
+	if b, ok := val.(*obj.BooleanObject); ok {
+		if b.Value {
+			return 1, true
+		}
+		return 0, true
+	}
+	return integralValue(val)
+}
+
+// evalConditionalExpression evaluates only the branch selected by the
+// condition. Arithmetic branches are converted to their common type, the one
+// of the branch not taken being inferred without evaluating it.
+func evalConditionalExpression(ce *ast.ConditionalExpression, env *obj.Environment) obj.Object {
+	condition := Eval(ce.Condition, env)
+	if condition.Type() == obj.ERROR_OBJ {
+		return condition
+	}
+	branch, other := ce.Consequence, ce.Alternative
+	if !IsTrue(condition) {
+		branch, other = other, branch
+	}
+	val := Eval(branch, env)
+	if val.Type() == obj.ERROR_OBJ {
+		return val
+	}
+	otherType := staticType(other, env)
+	switch v := val.(type) {
+	case *obj.IntegerObject:
+		if otherType == obj.FLOAT_OBJ {
+			return &obj.FloatObject{Value: float64(v.Value)}
+		}
+	case *obj.CharObject:
+		switch otherType {
+		case obj.FLOAT_OBJ:
+			return &obj.FloatObject{Value: float64(v.Value)}
+		case obj.INTEGER_OBJ:
+			return &obj.IntegerObject{Value: int64(v.Value)}
+		}
+	}
+	return val
+}
+
+// staticType infers the type exp evaluates to without evaluating it, an
+// empty type is returned when it cannot be told.
+func staticType(exp ast.Expression, env *obj.Environment) obj.ObjType {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return obj.INTEGER_OBJ
+	case *ast.FloatLiteral:
+		return obj.FLOAT_OBJ
+	case *ast.CharLiteral:
+		return obj.CHAR_OBJ
+	case *ast.BoolLiteral:
+		return obj.BOOLEAN_OBJ
+	case *ast.StringLiteral:
+		return obj.STRING_OBJ
+	case *ast.IdentifierExpression:
+		if val, ok := env.GetVar(exp.Value); ok {
+			return val.Type()
+		}
+	case *ast.CallExpression:
+		if val, ok := env.GetVar(exp.Function.String()); ok {
+			if fn, ok := val.(*obj.FunctionObject); ok {
+				return fn.ReturnType
+			}
+		}
+	case *ast.PrefixExpression:
+		switch exp.Token.TokenType {
+		case token.NOT:
+			return obj.BOOLEAN_OBJ
+		case token.TILDE:
+			return obj.INTEGER_OBJ
+		case token.MINUS, token.PLUS, token.INCR, token.DECR:
+			return staticType(exp.Exp, env)
+		}
+	case *ast.PostfixExpression:
+		return staticType(exp.Exp, env)
+	case *ast.InfixExpression:
+		switch exp.Token.TokenType {
+		case token.PLUS, token.MINUS, token.ASTER, token.SLASH:
+			left, right := staticType(exp.LeftExp, env), staticType(exp.RightExp, env)
+			if left == obj.FLOAT_OBJ || right == obj.FLOAT_OBJ {
+				return obj.FLOAT_OBJ
+			}
+			if isIntegralType(left) && isIntegralType(right) {
+				return obj.INTEGER_OBJ
+			}
+		case token.PERCENT, token.AMP, token.PIPE, token.XOR, token.LSHIFT, token.RSHIFT:
+			return obj.INTEGER_OBJ
+		case token.EQ, token.NE, token.LT, token.LE, token.GT, token.GE, token.AND, token.OR:
+			return obj.BOOLEAN_OBJ
+		}
+	case *ast.ConditionalExpression:
+		left, right := staticType(exp.Consequence, env), staticType(exp.Alternative, env)
+		if left == obj.FLOAT_OBJ || right == obj.FLOAT_OBJ {
+			return obj.FLOAT_OBJ
+		}
+		if left == right {
+			return left
+		}
+		if isIntegralType(left) && isIntegralType(right) {
+			return obj.INTEGER_OBJ
+		}
+	}
+	return ""
 }
 
-func evalInfixOROp(leftVal obj.Object, rightVal obj.Object) obj.Object {
-	return obj.GetBoolean(IsTrue(leftVal) || IsTrue(rightVal))
+func isIntegralType(t obj.ObjType) bool {
+	return t == obj.INTEGER_OBJ || t == obj.CHAR_OBJ
+}
+
+// evalLogicalExpression evaluates && and ||, the right operand is only
+// evaluated when the left one doesn't decide the result.
+func evalLogicalExpression(expr *ast.InfixExpression, env *obj.Environment) obj.Object {
+	leftVal := Eval(expr.LeftExp, env)
+	if leftVal.Type() == obj.ERROR_OBJ {
+		return leftVal
+	}
+	left := IsTrue(leftVal)
+	if expr.Token.TokenType == token.AND && !left {
+		return obj.FALSE
+	}
+	if expr.Token.TokenType == token.OR && left {
+		return obj.TRUE
+	}
+	rightVal := Eval(expr.RightExp, env)
+	if rightVal.Type() == obj.ERROR_OBJ {
+		return rightVal
+	}
+	return obj.GetBoolean(IsTrue(rightVal))
 }
 
 func getNumericValue(val obj.Object) (float64, bool) {
@@ -351,6 +740,8 @@ func IsTrue(val obj.Object) bool {
 		} else {
 			return false
 		}
+	case *obj.PointerObject:
+		return !val.IsNull()
 	default:
 		return true
 	}
@@ -372,20 +763,37 @@ func evalCallExpression(ce *ast.CallExpression, env *obj.Environment) obj.Object
 	if !ok {
 		return obj.NewError(fmt.Errorf("error calling function %s, identifier not associated with a function", ce.Function.String()))
 	}
+	funcObj, err := functionDefinition(ce.Function.String(), funcObj, env)
+	if err != nil {
+		return obj.NewError(err)
+	}
 	if len(funcObj.Params) != len(ce.Args) {
 		return obj.NewError(fmt.Errorf("error calling function %s, number of args and parameter mismatch, Parameters - %d, Args - %d", ce.Function.String(), len(funcObj.Params), len(ce.Args)))
 	}
 	newEnv := env.ExtendEnv()
+	// the parameters and locals of the call are freed once it returns
+	defer newEnv.Release()
 	// validate parameter argument pairs and assign args to params
 	for i, param := range funcObj.Params {
 		arg := Eval(ce.Args[i], env)
-		if obj.GetObjectType(param.Type) != arg.Type() {
-			return obj.NewError(fmt.Errorf("error calling function %s, type of parameter %s mismatch, expected %s, got %s", ce.Function, param.Identifier, param.Type, arg.Type()))
+		if arg.Type() == obj.ERROR_OBJ {
+			return arg
 		}
-		newEnv.SetVar(param.Identifier.Value, arg)
+		paramVal := getDefaultVal(param.Type, param.TypeName, param.Pointers, env)
+		if paramVal.Type() == obj.ERROR_OBJ {
+			return paramVal
+		}
+		converted, ok := convertForAssignment(paramVal, arg)
+		if !ok {
+			return obj.NewError(fmt.Errorf("error calling function %s, type of parameter %s mismatch, expected %s, got %s", ce.Function, param.Identifier, param.TypeString(), typeString(arg)))
+		}
+		newEnv.DeclareVar(param.Identifier.Value, converted)
 	}
 
 	returnObj := evalBlock(funcObj.Block, newEnv)
+	if returnObj.Type() == obj.ERROR_OBJ {
+		return returnObj
+	}
 	returnVal, ok := returnObj.(*obj.ReturnObject)
 
 	// validate return
@@ -399,20 +807,95 @@ func evalCallExpression(ce *ast.CallExpression, env *obj.Environment) obj.Object
 	if !ok {
 		return obj.NewError(fmt.Errorf("error calling function %s, expected return value of type %s, got none", ce.Function, funcObj.ReturnType))
 	}
+	if arr, ok := returnVal.Return.(*obj.ArrayObject); ok && funcObj.ReturnType == obj.POINTER_OBJ {
+		// an array returned from a pointer function decays like any array value
+		return arr.Decay()
+	}
 	if returnVal.Return.Type() != funcObj.ReturnType {
 		return obj.NewError(fmt.Errorf("error calling function %s, return value type mismatch, expected %s, got %s", ce.Function, funcObj.ReturnType, returnVal.Return.Type()))
 	}
 	return returnVal.Return
 }
 
-func evalArrayValExpressions(exps []ast.Expression, env *obj.Environment, objType obj.ObjType) ([]obj.Object, bool) {
+func evalArrayValExpressions(exps []ast.Expression, env *obj.Environment, defaultVal obj.Object) ([]obj.Object, obj.Object) {
 	var objs []obj.Object
 	for _, exp := range exps {
-		result := Eval(exp, env)
-		if result.Type() != objType {
-			return objs, false
+		result := evalInitializer(obj.Copy(defaultVal), exp, env)
+		if result.Type() == obj.ERROR_OBJ {
+			return objs, result
 		}
 		objs = append(objs, result)
 	}
-	return objs, true
+	return objs, nil
+}
+
+// zeroRow builds the zero element of an array with the inner dimensions
+// dims, for int m[2][3] it is an array of 3 ints.
+func zeroRow(zero obj.Object, dims []int) obj.Object {
+	elem := zero
+	for i := len(dims) - 1; i >= 0; i-- {
+		elem = obj.GetArrayObject(zero.Type(), dims[i], nil, elem)
+	}
+	return elem
+}
+
+func getDefaultVal(tknType token.TokenType, typeName string, pointers int, env *obj.Environment) obj.Object {
+	if !isStructType(tknType) {
+		if pointers > 0 {
+			return obj.GetDefaultPointer(tknType, pointers)
+		}
+		return obj.GetDefaultVal(tknType)
+	}
+	def, ok := env.GetStruct(typeName)
+	if !ok || def.Union != (tknType == token.UNION) {
+		kind := "struct"
+		if tknType == token.UNION {
+			kind = "union"
+		}
+		return obj.NewError(fmt.Errorf("type error: %s %s not declared", kind, typeName))
+	}
+	if pointers > 0 {
+		ptr := obj.GetDefaultPointer(tknType, pointers)
+		ptr.Struct = def
+		return ptr
+	}
+	return def.New()
+}
+
+// convertForAssignment checks that val can be stored where target is
+// stored, applying the implicit conversions C allows on assignment.
+func convertForAssignment(target obj.Object, val obj.Object) (obj.Object, bool) {
+	if st, ok := target.(*obj.StructObject); ok {
+		// structs are copied by value
+		valSt, ok := val.(*obj.StructObject)
+		if !ok || valSt.Def != st.Def {
+			return val, false
+		}
+		return obj.Copy(valSt), true
+	}
+	ptr, ok := target.(*obj.PointerObject)
+	if !ok {
+		return val, target.Type() == val.Type()
+	}
+	switch val := val.(type) {
+	case *obj.PointerObject:
+		return retypePointer(ptr, val)
+	case *obj.ArrayObject:
+		return retypePointer(ptr, val.Decay())
+	case *obj.IntegerObject:
+		// 0 is the null pointer constant
+		return &obj.PointerObject{DataType: ptr.DataType, Depth: ptr.Depth, Struct: ptr.Struct}, val.Value == 0
+	}
+	return val, false
+}
+
+func retypePointer(target *obj.PointerObject, val *obj.PointerObject) (obj.Object, bool) {
+	if val.SamePointee(target) {
+		return val, true
+	}
+	// void * converts to and from any other object pointer
+	if target.IsVoid() || val.IsVoid() {
+		return &obj.PointerObject{Addr: val.Addr, DataType: target.DataType, Depth: target.Depth, Struct: target.Struct}, true
+	}
+	return val, false
 }
@@ -1,6 +1,9 @@
 package eval
 
 import (
+	"bytes"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/mohamedirfanam/cynterpreter/eval/obj"
@@ -43,6 +46,42 @@ func TestLiteralExpression(t *testing.T) {
 	}
 }
 
+// testEval parses and evaluates input in a new environment and returns the
+// result of the program, failing the test on parser or evaluation errors.
+func testEval(t *testing.T, input string) obj.Object {
+	t.Helper()
+	p := parser.New(input)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("Parser errors for %q: %v", input, p.Errors())
+	}
+	result := Eval(program, obj.NewEnv())
+	if result.Type() == obj.ERROR_OBJ {
+		t.Fatalf("Evaluation error for %q: %s", input, result.String())
+	}
+	return result
+}
+
+// testEvalError parses and evaluates input and checks that it fails with an
+// error containing expected.
+func testEvalError(t *testing.T, input string, expected string) {
+	t.Helper()
+	p := parser.New(input)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("Parser errors for %q: %v", input, p.Errors())
+	}
+	result := Eval(program, obj.NewEnv())
+	errObj, ok := result.(*obj.ErrorObject)
+	if !ok {
+		t.Errorf("Expected an error for %q, got %s", input, result.Type())
+		return
+	}
+	if !strings.Contains(errObj.String(), expected) {
+		t.Errorf("Expected error containing %q for %q, got %q", expected, input, errObj.String())
+	}
+}
+
 func testIntegerObject(t *testing.T, object obj.Object, value int) {
 	intObject, ok := object.(*obj.IntegerObject)
 	if !ok {
@@ -233,6 +272,31 @@ func TestInfixOps(t *testing.T) {
 		{"10 % -3;", 1},
 		{"-10 % -3;", -1},
 
+		// Bitwise tests
+		{"12 & 10;", 8},
+		{"12 | 10;", 14},
+		{"12 ^ 10;", 6},
+		{"~12;", -13},
+		{"~0;", -1},
+		{"3 << 4;", 48},
+		{"-16 >> 2;", -4},
+		{"'a' & ~32;", 65},
+		{"1 | 2 ^ 3 & 4;", 3},
+		{"1 + 2 << 1;", 6},
+		{"6 & 3 == 3;", 0},
+		{"0xDEADBEEF & 0xFFFF;", 0xBEEF},
+		{"0x0F | 0xF0;", 255},
+		{"017 + 0b101;", 20},
+		{"1UL << 30;", 1 << 30},
+		{"1 << 31;", -2147483648},
+		{"-1 << 31;", -2147483648},
+		{"0x7FFFFFFF << 1;", -2},
+		{"-2147483648 >> 31;", -1},
+		{"~2147483647;", -2147483648},
+		{"0xFFFFFFFF | 0;", -1},
+		{".5 + 1e-1;", 0.6},
+		{"1.5e2 - 2.5f;", 147.5},
+
 		// Comparison tests - Greater Than
 		{"5 > 3;", true},
 		{"3 > 5;", false},
@@ -371,3 +435,300 @@ func TestInfixOps(t *testing.T) {
 		}
 	}
 }
+
+func TestBitwiseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5 & 2;", "type error: Invalid operand types for bitwise operator '&', expected integer & integer but got FLOAT_OBJ & INTEGER_OBJ"},
+		{"1 | 2.0;", "type error: Invalid operand types for bitwise operator '|', expected integer | integer but got INTEGER_OBJ | FLOAT_OBJ"},
+		{`"a" ^ 1;`, "type error: Invalid operand types for bitwise operator '^', expected integer ^ integer but got STRING_OBJ ^ INTEGER_OBJ"},
+		{"~1.5;", "type error: Invalid operand type for bitwise NOT operator, expected integer but got FLOAT_OBJ"},
+		{"1 << 64;", "runtime error: shift count 64 out of range for int"},
+		{"1 << 32;", "runtime error: shift count 32 out of range for int"},
+		{"1 << 40;", "runtime error: shift count 40 out of range for int"},
+		{"1 >> -1;", "runtime error: shift count -1 out of range for int"},
+		{"~undefined_var;", "1:2: variable error: variable undefined_var not declared in this scope"},
+		{"-undefined_var;", "1:2: variable error: variable undefined_var not declared in this scope"},
+		{"!undefined_var;", "1:2: variable error: variable undefined_var not declared in this scope"},
+	}
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestIntegerOverflow(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"2147483647 + 1;", -2147483648},
+		{"-2147483648 - 1;", 2147483647},
+		{"65536 * 65536;", 0},
+		{"-2147483648 / -1;", -2147483648},
+		{"-(-2147483648);", -2147483648},
+		{"int x = 0x7FFFFFFF; x++; x;", -2147483648},
+		{"int x = -2147483648; --x;", 2147483647},
+		{"int x = 0x7FFFFFFF; x += 2; x;", -2147483647},
+		{"int x = 0xFFFFFFFF; x;", -1},
+		{"int x = 4294967295u; x;", -1},
+		{"int x = 0x80000000; x;", -2147483648},
+		{"-0x80000000;", -2147483648},
+		{"-1u;", -1},
+		{"int x = 5L; x;", 5},
+		{"0xFFFFFFFFul;", -1},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int x = 3000000000;", "1:9: type error: integer constant 3000000000 of type long does not fit in an int"},
+		{"2147483648;", "type error: integer constant 2147483648 of type long does not fit in an int"},
+		{"-2147483649;", "type error: integer constant 2147483649 of type long does not fit in an int"},
+		{"0x100000000;", "type error: integer constant 0x100000000 of type long does not fit in an int"},
+		{"4294967296u;", "type error: integer constant 4294967296u of type unsigned long does not fit in an int"},
+	}
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestIncrementDecrement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int i = 5; i++;", 5},
+		{"int i = 5; ++i;", 6},
+		{"int i = 5; i--;", 5},
+		{"int i = 5; --i;", 4},
+		{"int i = 5; i++; i;", 6},
+		{"int i = 5; int j = i++ + i; j;", 11},
+		{"int i = 5; int j = ++i * 2; j;", 12},
+		{"int i = 5; -i++;", -5},
+		{"int a[3] = {1, 2, 3}; int k = 0; a[k++]++; a[0] * 10 + k;", 21},
+		{"int a[3] = {1, 2, 3}; --a[2]; a[2];", 2},
+		{"int a[3] = {1, 2, 3}; int *p = a; p++; *p;", 2},
+		{"int a[3] = {1, 2, 3}; int *p = a; *p++ = 7; *p + a[0];", 9},
+		{"int a[3] = {1, 2, 3}; int *p = a + 2; --p; (*p)++; a[1];", 3},
+		{"struct P { int x; }; struct P s; s.x++; ++s.x; s.x;", 2},
+		{"struct P { int x; }; struct P s; struct P *ps = &s; ps->x--; s.x;", -1},
+		{"int sum = 0; for (int i = 0; i < 4; i++) { sum += i; } sum;", 6},
+		{"int sum = 0; for (int i = 3; i > 0; --i) { sum += i; } sum;", 6},
+		{"int n = 3; int c = 0; while (n--) { c += 1; } c * 10 + n;", 29},
+	}
+
+	for i, tt := range tests {
+		env := obj.NewEnv()
+		p := parser.New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+
+		var result obj.Object
+		for _, stmt := range program.Statements {
+			result = Eval(stmt, env)
+			if result.Type() == obj.ERROR_OBJ {
+				t.Fatalf("[%d] - Evaluation error: %s", i, result.String())
+			}
+		}
+		testIntegerObject(t, result, tt.expected)
+	}
+
+	charEnv := obj.NewEnv()
+	charP := parser.New("char c = 'a'; c++; c;")
+	var result obj.Object
+	for _, stmt := range charP.ParseProgram().Statements {
+		result = Eval(stmt, charEnv)
+	}
+	testCharObject(t, result, 'b')
+
+	testCharObject(t, testEval(t, `string s = "abc"; s[1]++; s[1];`), 'c')
+	testStringObject(t, testEval(t, `string s = "abc"; int i = 0; --s[i++]; ++s[2]; s;`), "`bd")
+	testIntegerObject(t, testEval(t, `string s = "abc"; int i = 0; s[i++]--; i;`), 1)
+	testCharObject(t, testEval(t, `string s = "abc"; s[0]++;`), 'a')
+
+	floatEnv := obj.NewEnv()
+	floatP := parser.New("float f = 1.5; f--; f;")
+	for _, stmt := range floatP.ParseProgram().Statements {
+		result = Eval(stmt, floatEnv)
+	}
+	testFloatObject(t, result, 0.5)
+
+	errorTests := []string{
+		"5++;",
+		"--(1 + 2);",
+		"x++;",
+		"int a[2] = {1, 2}; a++;",
+		"struct S { int a[2]; }; struct S s; s.a--;",
+		"bool b = true; b++;",
+		`string s = "hi"; ++s;`,
+		`string s = "hi"; s[2]++;`,
+	}
+	for i, input := range errorTests {
+		env := obj.NewEnv()
+		p := parser.New(input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		for _, stmt := range program.Statements {
+			result = Eval(stmt, env)
+		}
+		if result.Type() != obj.ERROR_OBJ {
+			t.Errorf("[%d] - Expected error for %q, got %s", i, input, result.Type())
+		}
+	}
+}
+
+func TestConditionalExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1 ? 2 : 3;", 2},
+		{"0 ? 2 : 3;", 3},
+		{"int x = -4; x < 0 ? -x : x;", 4},
+		{"0 ? 1 : 0 ? 2 : 3;", 3},
+		{"1 ? 0 ? 1 : 2 : 3;", 2},
+		{"int i = 0; 1 ? i++ : i--; i;", 1},
+		{"int i = 0; 0 ? i++ : i--; i;", -1},
+		{"int max(int a, int b) { return a > b ? a : b; } max(3, 8) + max(5, 1);", 13},
+		{"1 ? 1 : 2.5;", 1.0},
+		{"0 ? 1 : 2.5;", 2.5},
+		{"float f = 1.5; 1 ? 2 : f;", 2.0},
+		{"1 ? 'a' : 2;", 97},
+		{"1 ? 'a' : 'b';", "a"},
+		{"true ? \"yes\" : \"no\";", "yes"},
+		{"int x = 5; int y = x > 3 ? 10 : 20; y;", 10},
+	}
+
+	for i, tt := range tests {
+		env := obj.NewEnv()
+		p := parser.New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+
+		var result obj.Object
+		for _, stmt := range program.Statements {
+			result = Eval(stmt, env)
+			if result.Type() == obj.ERROR_OBJ {
+				t.Fatalf("[%d] - Evaluation error: %s", i, result.String())
+			}
+		}
+		switch val := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, result, val)
+		case float64:
+			testFloatObject(t, result, val)
+		case string:
+			if result.Type() == obj.CHAR_OBJ {
+				testCharObject(t, result, val[0])
+			} else {
+				testStringObject(t, result, val)
+			}
+		}
+	}
+
+	// only the selected branch is evaluated, the other one would fail
+	env := obj.NewEnv()
+	p := parser.New("1 ? 5 : undefined;")
+	result := Eval(p.ParseProgram().Statements[0], env)
+	testIntegerObject(t, result, 5)
+}
+
+func TestEvaluationOrder(t *testing.T) {
+	trace := "int t(int v){ printf(\"%d;\", v); return v; } "
+	tests := []struct {
+		input    string
+		expected interface{}
+		output   string
+	}{
+		// && and || only evaluate the right operand when needed
+		{"t(0) && t(1);", false, "0;"},
+		{"t(1) && t(2);", true, "1;2;"},
+		{"t(1) || t(2);", true, "1;"},
+		{"t(0) || t(2);", true, "0;2;"},
+		{"t(0) || t(0) && t(3);", false, "0;0;"},
+		{"t(1) && t(0) || t(3);", true, "1;0;3;"},
+		{"int arr[3] = {1, 2, 3}; int i = 3; i < 3 && arr[i] > 0;", false, ""},
+		{"int *p = 0; p != 0 && *p == 1;", false, ""},
+		{"int *p = 0; p == 0 || *p == 1;", true, ""},
+		// other operators evaluate left then right
+		{"t(1) + t(2) * t(3);", 7, "1;2;3;"},
+		{"t(4) - t(5);", -1, "4;5;"},
+		{"t(6) < t(7);", true, "6;7;"},
+		{"t(8) == t(9);", false, "8;9;"},
+		{"int add(int a, int b){ return a + b; } add(t(1), t(2));", 3, "1;2;"},
+	}
+
+	defer func() { Stdout = os.Stdout }()
+	for i, tt := range tests {
+		var out bytes.Buffer
+		Stdout = &out
+		env := obj.NewEnv()
+		p := parser.New(trace + tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+
+		var result obj.Object
+		for _, stmt := range program.Statements {
+			result = Eval(stmt, env)
+			if result.Type() == obj.ERROR_OBJ {
+				t.Fatalf("[%d] - Evaluation error: %s", i, result.String())
+			}
+		}
+
+		switch val := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, result, val)
+		case bool:
+			testBooleanObject(t, result, val)
+		default:
+			t.Fatalf("Test [%d]: Unsupported expected type %T", i, val)
+		}
+		if out.String() != tt.output {
+			t.Errorf("[%d] - Output not correct, expected %q, got %q", i, tt.output, out.String())
+		}
+	}
+}
+
+func TestEscapeSequencesOutput(t *testing.T) {
+	tests := []struct {
+		input  string
+		output string
+	}{
+		{`int x = 42; printf("%d\n", x);`, "42\n"},
+		{`printf("a\tb\\c\"d\"\n");`, "a\tb\\c\"d\"\n"},
+		{`printf("\x48\151%c", '\x21');`, "Hi!"},
+		{`printf("%s|%c", "a\0b", '\101');`, "a\x00b|A"},
+	}
+
+	defer func() { Stdout = os.Stdout }()
+	for i, tt := range tests {
+		var out bytes.Buffer
+		Stdout = &out
+		env := obj.NewEnv()
+		p := parser.New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		if result := Eval(program, env); result.Type() == obj.ERROR_OBJ {
+			t.Fatalf("[%d] - Evaluation error: %s", i, result.String())
+		}
+		if out.String() != tt.output {
+			t.Errorf("[%d] - Wrong output, expected %q, got %q", i, tt.output, out.String())
+		}
+	}
+}
@@ -4,60 +4,198 @@ import (
 	"fmt"
 )
 
+// Environment is a scope mapping variable names to their addresses, the
+// values themselves live in the Memory shared by every environment of a
+// program. Names not declared in a scope are looked up in the enclosing
+// ones, up to the file scope holding the globals, functions and struct types.
 type Environment struct {
-	memory map[string]Object
+	store   map[string]int64
+	structs map[string]*StructType
+	memory  *Memory
+	outer   *Environment // enclosing scope, nil for the file scope
+	mark    int64        // first address allocated in the scope
 }
 
 func NewEnv() *Environment {
-	memory := make(map[string]Object)
-	return &Environment{
-		memory: memory,
+	return &Environment{memory: NewMemory()}
+}
+
+func (env *Environment) Memory() *Memory {
+	return env.memory
+}
+
+// NewScope returns a scope nested in env, for a block or a loop.
+func (env *Environment) NewScope() *Environment {
+	return &Environment{memory: env.memory, outer: env, mark: env.memory.next}
+}
+
+// Release frees the variables declared in the scope and in the scopes nested
+// in it, once it exits, so that the next declarations reuse their addresses.
+func (env *Environment) Release() {
+	env.memory.Free(env.mark)
+}
+
+// ExtendEnv returns the scope of a function call, which sees the global
+// declarations but none of the caller's locals.
+func (env *Environment) ExtendEnv() *Environment {
+	return env.fileScope().NewScope()
+}
+
+// fileScope returns the environment of the global declarations.
+func (env *Environment) fileScope() *Environment {
+	for env.outer != nil {
+		env = env.outer
 	}
+	return env
 }
 
-func (env *Environment) SetVar(varname string, val Object) {
-	env.memory[varname] = val
+func (env *Environment) resolve(varname string) (int64, bool) {
+	for ; env != nil; env = env.outer {
+		if addr, ok := env.store[varname]; ok {
+			return addr, true
+		}
+	}
+	return 0, false
+}
+
+// DeclareVar allocates a new variable in env, hiding any variable of the
+// same name in the enclosing scopes.
+func (env *Environment) DeclareVar(varname string, val Object) {
+	if env.store == nil {
+		env.store = make(map[string]int64)
+	}
+	env.store[varname] = env.memory.Alloc(val)
+}
+
+// DeclaredInScope reports whether varname is declared in env itself rather
+// than in an enclosing scope.
+func (env *Environment) DeclaredInScope(varname string) bool {
+	_, ok := env.store[varname]
+	return ok
 }
 
-func (env *Environment) GetIndexVar(varname string, index int) (Object, error) {
-	object, ok := env.memory[varname]
+// SetVar stores val in the variable varname resolves to, declaring it in
+// env when it does not exist yet.
+func (env *Environment) SetVar(varname string, val Object) {
+	addr, ok := env.resolve(varname)
 	if !ok {
-		return nil, fmt.Errorf("%s variable doesn't exist", varname)
+		env.DeclareVar(varname, val)
+		return
+	}
+	if old, ok := env.memory.Value(addr); ok && isAggregate(old) {
+		Overwrite(old, val)
+		return
+	}
+	env.memory.Store(addr, val)
+}
+
+// SetField stores val in the member index of st, and in the union st is
+// part of, if any.
+func (env *Environment) SetField(st *StructObject, index int, val Object) {
+	st.SetField(index, val)
+	if st.Addr != 0 {
+		env.memory.written(st.Addr+st.Offset(index), st.Vals[index])
+	}
+}
+
+// SetStruct declares a struct or union tag, tags live in their own
+// namespace apart from variables.
+func (env *Environment) SetStruct(name string, def *StructType) {
+	if env.structs == nil {
+		env.structs = make(map[string]*StructType)
+	}
+	env.structs[name] = def
+}
+
+// StructDeclaredInScope reports whether the tag name is declared in env
+// itself rather than in an enclosing scope.
+func (env *Environment) StructDeclaredInScope(name string) bool {
+	_, ok := env.structs[name]
+	return ok
+}
+
+func (env *Environment) GetStruct(name string) (*StructType, bool) {
+	for ; env != nil; env = env.outer {
+		if def, ok := env.structs[name]; ok {
+			return def, true
+		}
+	}
+	return nil, false
+}
+
+func (env *Environment) GetAddr(varname string) (int64, bool) {
+	return env.resolve(varname)
+}
+
+// Index returns the element index of the array, string or pointer object,
+// an element of a multi-dimensional array is its row.
+func (env *Environment) Index(object Object, index int) (Object, error) {
+	if err := CheckIndex(object, "", index, false); err != nil {
+		return nil, err
 	}
 	switch val := object.(type) {
 	case *ArrayObject:
-		if index >= val.Length {
-			return nil, fmt.Errorf("invalid index, index greater than lenghth of the string %d", val.Length)
-		}
 		return val.Vals[index], nil
 	case *StringObject:
-		if index >= len(val.Value) {
-			return nil, fmt.Errorf("invalid index, index greater than lenghth of the string %d", len(val.Value))
-		}
 		return &CharObject{Value: val.Value[index]}, nil
+	case *PointerObject:
+		return env.memory.Deref(val.Offset(int64(index)))
 	}
 	return object, nil
 }
 
-func (env *Environment) SetIndexVar(varname string, index int, updateVal Object) error {
-	object, ok := env.memory[varname]
-	if !ok {
-		return fmt.Errorf("%s variable doesn't exist", varname)
+// CheckIndex reports an index outside of the array or string container,
+// named name in the error when it is not empty. end also allows the index
+// one past the last element, whose address may be taken. Pointers are left
+// to the memory, which faults on an invalid address.
+func CheckIndex(container Object, name string, index int, end bool) error {
+	var kind string
+	var length int
+	switch val := container.(type) {
+	case *ArrayObject:
+		kind, length = "array", val.Length
+	case *StringObject:
+		kind, length = "string", len(val.Value)
+	default:
+		return nil
+	}
+	limit := length
+	if end {
+		limit++
+	}
+	if index >= 0 && index < limit {
+		return nil
+	}
+	if name != "" {
+		kind += " " + name
+	}
+	return fmt.Errorf("invalid index, index %d out of bounds for %s of length %d", index, kind, length)
+}
+
+// SetIndex stores updateVal in the element index of the array, string or
+// pointer object.
+func (env *Environment) SetIndex(object Object, index int, updateVal Object) error {
+	if err := CheckIndex(object, "", index, false); err != nil {
+		return err
 	}
 	switch val := object.(type) {
 	case *ArrayObject:
-		if index >= val.Length {
-			return fmt.Errorf("invalid index, index greater than lenghth of the string %d", val.Length)
-		}
 		if val.DataType != updateVal.Type() {
-			return fmt.Errorf("type error,cannot assign %s to %s", object.Type(), val.DataType)
+			return fmt.Errorf("type error,cannot assign %s to %s", updateVal.Type(), val.DataType)
+		}
+		if old, ok := val.Vals[index].(*StructObject); ok {
+			if st, ok := updateVal.(*StructObject); !ok || st.Def != old.Def {
+				return fmt.Errorf("type error,cannot assign %s to %s", updateVal.Type(), old.Def)
+			}
+			Overwrite(old, updateVal)
+		} else {
+			val.Vals[index] = updateVal
+		}
+		if val.Addr != 0 {
+			env.memory.written(val.Addr+int64(index)*val.ElemSize(), val.Vals[index])
 		}
-		val.Vals[index] = updateVal
 		return nil
 	case *StringObject:
-		if index >= len(val.Value) {
-			return fmt.Errorf("invalid index, index greater than lenghth of the string %d", len(val.Value))
-		}
 		newChar, ok := updateVal.(*CharObject)
 		if !ok {
 			return fmt.Errorf("expectecd a char literal to assign to string indexed")
@@ -65,41 +203,25 @@ func (env *Environment) SetIndexVar(varname string, index int, updateVal Object)
 		b := []byte(val.Value)
 		b[index] = newChar.Value
 		val.Value = string(b)
-	}
-	return nil
-}
-
-func (env *Environment) GetVar(varname string) (Object, bool) {
-	val, ok := env.memory[varname]
-	if ok {
-		return val, true
-	}
-	return nil, false
-}
-
-func (env *Environment) ExtendEnv() *Environment {
-	newEnv := NewEnv()
-	for k, v := range env.memory {
-		if v.Type() == FUNCTION_OBJ {
-			newEnv.memory[k] = v
+	case *PointerObject:
+		if !val.SameType(updateVal) {
+			return fmt.Errorf("type error,cannot assign %s to %s", updateVal.Type(), val.PointeeType())
 		}
+		return env.memory.Assign(val.Offset(int64(index)), updateVal)
 	}
-	return newEnv
+	return nil
 }
 
-func (env *Environment) CopyEnv() *Environment {
-	newEnv := NewEnv()
-	for k, v := range env.memory {
-		newEnv.memory[k] = v
-	}
-	return newEnv
+// GetGlobal returns the file-scope variable varname, even when a local of
+// the same name hides it.
+func (env *Environment) GetGlobal(varname string) (Object, bool) {
+	return env.fileScope().GetVar(varname)
 }
 
-func (env *Environment) UpdateVals(newEnv *Environment) {
-	for k := range env.memory {
-		val, ok := newEnv.memory[k]
-		if ok {
-			env.memory[k] = val
-		}
+func (env *Environment) GetVar(varname string) (Object, bool) {
+	addr, ok := env.resolve(varname)
+	if !ok {
+		return nil, false
 	}
+	return env.memory.Value(addr)
 }
@@ -0,0 +1,273 @@
+package obj
+
+import (
+	"fmt"
+)
+
+// Address of the first allocation, everything below it stays unmapped so
+// that dereferencing a null pointer is always caught.
+const baseAddress int64 = 0x1000
+
+// Memory simulates a byte-addressable address space. Every allocation
+// reserves as many bytes as the object occupies and each scalar inside it
+// (a variable, an array element) gets a slot at its own address.
+type Memory struct {
+	slots      map[int64]*slot
+	aggregates map[int64]Object
+	next       int64
+}
+
+// A slot points into the slice that owns the value, so arrays share their
+// backing storage with the memory and never go out of sync.
+type slot struct {
+	vals  []Object
+	index int
+	// union is set for the bytes of a union, which its members share. Each
+	// access reads or writes the bytes at offset inside the union as the
+	// type of the pointer used.
+	union  *StructObject
+	offset int64
+}
+
+// get reads the value of the slot, a byte of a union when no type is given.
+func (s *slot) get() Object {
+	if s.union != nil {
+		return s.union.load(s.offset, &CharObject{})
+	}
+	return s.vals[s.index]
+}
+
+func (s *slot) set(val Object) {
+	if s.union != nil {
+		s.union.store(s.offset, val)
+		return
+	}
+	s.vals[s.index] = val
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		slots:      make(map[int64]*slot),
+		aggregates: make(map[int64]Object),
+		next:       baseAddress,
+	}
+}
+
+func SizeOfType(t ObjType) int64 {
+	switch t {
+	case CHAR_OBJ, BOOLEAN_OBJ:
+		return 1
+	case INTEGER_OBJ:
+		return 4
+	default:
+		return 8
+	}
+}
+
+func SizeOf(val Object) int64 {
+	switch val := val.(type) {
+	case *ArrayObject:
+		return int64(val.Length) * val.ElemSize()
+	case *StructObject:
+		return val.Def.Size()
+	default:
+		return SizeOfType(val.Type())
+	}
+}
+
+// Alloc reserves memory for val and returns its address.
+func (m *Memory) Alloc(val Object) int64 {
+	size := SizeOf(val)
+	if size == 0 {
+		size = 1
+	}
+	addr := m.next
+	if rem := addr % 8; rem != 0 {
+		addr += 8 - rem
+	}
+	m.next = addr + size
+	m.place(val, addr)
+	return addr
+}
+
+// Free releases every allocation at or above addr. Scopes are opened and
+// closed in stack order, so freeing from the mark of a scope leaves the
+// variables of the enclosing ones in place.
+func (m *Memory) Free(addr int64) {
+	for a := addr; a < m.next; a++ {
+		delete(m.slots, a)
+		delete(m.aggregates, a)
+	}
+	if addr < m.next {
+		m.next = addr
+	}
+}
+
+func (m *Memory) place(val Object, addr int64) {
+	switch val := val.(type) {
+	case *ArrayObject:
+		val.Addr = addr
+		m.placeAggregate(val, addr)
+		size := val.ElemSize()
+		for i := range val.Vals {
+			m.placeElem(val.Vals, i, addr+int64(i)*size)
+		}
+	case *StructObject:
+		val.Addr = addr
+		m.placeAggregate(val, addr)
+		if val.Def.Union {
+			// the members overlap, they get their addresses but each byte
+			// is accessed through the union
+			for _, member := range val.Vals {
+				m.place(member, addr)
+			}
+			for offset := range int64(len(val.bytes)) {
+				m.slots[addr+offset] = &slot{union: val, offset: offset}
+			}
+			return
+		}
+		for i := range val.Vals {
+			m.placeElem(val.Vals, i, addr+val.Offset(i))
+		}
+	default:
+		m.slots[addr] = &slot{vals: []Object{val}}
+	}
+}
+
+// placeAggregate registers an array or struct at its base address, unless
+// an enclosing aggregate starting at the same address already did.
+func (m *Memory) placeAggregate(val Object, addr int64) {
+	if _, ok := m.aggregates[addr]; !ok {
+		m.aggregates[addr] = val
+	}
+}
+
+func (m *Memory) placeElem(vals []Object, i int, addr int64) {
+	if isAggregate(vals[i]) {
+		m.place(vals[i], addr)
+		return
+	}
+	m.slots[addr] = &slot{vals: vals, index: i}
+}
+
+// Load reads the scalar stored at addr.
+func (m *Memory) Load(addr int64) (Object, error) {
+	if addr == 0 {
+		return nil, fmt.Errorf("segmentation fault: null pointer dereference")
+	}
+	s, ok := m.slots[addr]
+	if !ok {
+		return nil, fmt.Errorf("segmentation fault: invalid memory access at address 0x%x", addr)
+	}
+	return s.get(), nil
+}
+
+// Store overwrites the scalar stored at addr.
+func (m *Memory) Store(addr int64, val Object) error {
+	if addr == 0 {
+		return fmt.Errorf("segmentation fault: null pointer dereference")
+	}
+	s, ok := m.slots[addr]
+	if !ok {
+		return fmt.Errorf("segmentation fault: invalid memory access at address 0x%x", addr)
+	}
+	s.set(val)
+	return nil
+}
+
+// Value returns the whole object allocated at addr, arrays included.
+func (m *Memory) Value(addr int64) (Object, bool) {
+	if val, ok := m.aggregates[addr]; ok {
+		return val, true
+	}
+	s, ok := m.slots[addr]
+	if !ok {
+		return nil, false
+	}
+	return s.get(), true
+}
+
+// LoadStruct returns the struct of type def stored at addr. A struct nested
+// at the start of another aggregate shares its address, so the lookup
+// descends through first members until the type matches.
+func (m *Memory) LoadStruct(addr int64, def *StructType) (*StructObject, error) {
+	if addr == 0 {
+		return nil, fmt.Errorf("segmentation fault: null pointer dereference")
+	}
+	if st := findStruct(m.aggregates[addr], def); st != nil {
+		return st, nil
+	}
+	return nil, fmt.Errorf("segmentation fault: no %s at address 0x%x", def, addr)
+}
+
+// findStruct returns the struct of type def at the start of val, searching
+// the first members of structs and arrays and every member of unions.
+func findStruct(val Object, def *StructType) *StructObject {
+	switch agg := val.(type) {
+	case *StructObject:
+		if agg.Def == def {
+			return agg
+		}
+		if agg.Def.Union {
+			for _, member := range agg.Vals {
+				if st := findStruct(member, def); st != nil {
+					return st
+				}
+			}
+			return nil
+		}
+		if len(agg.Vals) > 0 {
+			return findStruct(agg.Vals[0], def)
+		}
+	case *ArrayObject:
+		if len(agg.Vals) > 0 {
+			return findStruct(agg.Vals[0], def)
+		}
+	}
+	return nil
+}
+
+// Deref reads the object ptr points to, the whole struct for pointers to
+// structs. A row is read as the pointer to its first element it decays to.
+func (m *Memory) Deref(ptr *PointerObject) (Object, error) {
+	if ptr.IsRow() {
+		row := ptr.Row()
+		if _, err := m.Deref(row); err != nil {
+			return nil, err
+		}
+		return row, nil
+	}
+	if ptr.Depth == 1 && ptr.Struct != nil {
+		return m.LoadStruct(ptr.Addr, ptr.Struct)
+	}
+	if s, ok := m.slots[ptr.Addr]; ok && s.union != nil {
+		return s.union.load(s.offset, zeroPointee(ptr)), nil
+	}
+	return m.Load(ptr.Addr)
+}
+
+// Assign stores val where ptr points to, structs are copied member by member.
+func (m *Memory) Assign(ptr *PointerObject, val Object) error {
+	if ptr.IsRow() {
+		return fmt.Errorf("type error: array is not assignable")
+	}
+	if ptr.Depth == 1 && ptr.Struct != nil {
+		st, err := m.LoadStruct(ptr.Addr, ptr.Struct)
+		if err != nil {
+			return err
+		}
+		Overwrite(st, val)
+		m.written(ptr.Addr, st)
+		return nil
+	}
+	return m.Store(ptr.Addr, val)
+}
+
+// written updates the union, if any, whose bytes addr is part of after val
+// was written there in place, so that the other members of the union see
+// the new value.
+func (m *Memory) written(addr int64, val Object) {
+	if s, ok := m.slots[addr]; ok && s.union != nil {
+		s.union.store(s.offset, val)
+	}
+}
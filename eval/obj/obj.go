@@ -2,6 +2,7 @@ package obj
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/mohamedirfanam/cynterpreter/lexer/token"
@@ -22,12 +23,18 @@ const (
 	RETURN_OBJ   ObjType = "RETURN_OBJ"
 	RESULTS_OBJ  ObjType = "RESULTS_OBJ"
 	ARRAY_OBJ    ObjType = "ARRAY_OBJ"
+	POINTER_OBJ  ObjType = "POINTER_OBJ"
+	STRUCT_OBJ   ObjType = "STRUCT_OBJ"
+	BREAK_OBJ    ObjType = "BREAK_OBJ"
+	CONTINUE_OBJ ObjType = "CONTINUE_OBJ"
 )
 
 var (
-	TRUE  = &BooleanObject{Value: true}
-	FALSE = &BooleanObject{Value: false}
-	NULL  = &NullObject{}
+	TRUE     = &BooleanObject{Value: true}
+	FALSE    = &BooleanObject{Value: false}
+	NULL     = &NullObject{}
+	BREAK    = &BreakObject{}
+	CONTINUE = &ContinueObject{}
 )
 
 func GetObjectType(tknType token.TokenType) ObjType {
@@ -46,6 +53,8 @@ func GetObjectType(tknType token.TokenType) ObjType {
 		return FLOAT_OBJ
 	case token.VOID:
 		return NULL_OBJ
+	case token.STRUCT, token.UNION:
+		return STRUCT_OBJ
 	default:
 		return ERROR_OBJ
 	}
@@ -69,6 +78,13 @@ func GetDefaultVal(tknType token.TokenType) Object {
 	}
 }
 
+func GetDefaultPointer(tknType token.TokenType, depth int) *PointerObject {
+	return &PointerObject{
+		DataType: GetObjectType(tknType),
+		Depth:    depth,
+	}
+}
+
 func ExtractVal(object Object) any {
 	switch o := object.(type) {
 	case *IntegerObject:
@@ -105,6 +121,7 @@ func (n *NullObject) String() string {
 // Error Object
 type ErrorObject struct {
 	Error error
+	Pos   token.Position // where the error was raised, set by the evaluator
 }
 
 func (e *ErrorObject) Type() ObjType {
@@ -112,6 +129,9 @@ func (e *ErrorObject) Type() ObjType {
 }
 
 func (e *ErrorObject) String() string {
+	if e.Pos.IsValid() {
+		return e.Pos.String() + ": " + e.Error.Error()
+	}
 	return e.Error.Error()
 }
 
@@ -205,6 +225,30 @@ func (r *ReturnObject) String() string {
 	return r.Return.String()
 }
 
+// Break Object, signals a break statement to the enclosing loop or switch
+type BreakObject struct {
+}
+
+func (b *BreakObject) Type() ObjType {
+	return BREAK_OBJ
+}
+
+func (b *BreakObject) String() string {
+	return "break"
+}
+
+// Continue Object, signals a continue statement to the enclosing loop
+type ContinueObject struct {
+}
+
+func (c *ContinueObject) Type() ObjType {
+	return CONTINUE_OBJ
+}
+
+func (c *ContinueObject) String() string {
+	return "continue"
+}
+
 // Results Object
 type ResultsObject struct {
 	Results []Object
@@ -227,11 +271,13 @@ func (r *ResultsObject) String() string {
 	return str.String()
 }
 
-// Function Object
+// Function Object, the Block is nil while only a prototype of the function
+// has been declared
 type FunctionObject struct {
 	ReturnType ObjType
 	Block      *ast.Block
 	Params     []*ast.Parameter
+	Variadic   bool
 }
 
 func (f *FunctionObject) Type() ObjType {
@@ -247,6 +293,7 @@ func GetFunctionObject(returnType ObjType, fl *ast.FunctionLiteral) Object {
 		ReturnType: returnType,
 		Block:      fl.Block,
 		Params:     fl.Params,
+		Variadic:   fl.Variadic,
 	}
 }
 
@@ -255,6 +302,7 @@ type ArrayObject struct {
 	DataType ObjType
 	Length   int
 	Vals     []Object
+	Addr     int64
 }
 
 func (arr *ArrayObject) Type() ObjType {
@@ -265,10 +313,144 @@ func (arr *ArrayObject) String() string {
 	return ""
 }
 
-func GetArrayObject(dataType ObjType, length int, vals []Object) Object {
+func (arr *ArrayObject) ElemSize() int64 {
+	if len(arr.Vals) > 0 {
+		return SizeOf(arr.Vals[0])
+	}
+	return SizeOfType(arr.DataType)
+}
+
+// Decay converts the array to a pointer to its first element, the way C
+// does whenever an array is used as a value. The first element of a
+// multi-dimensional array is its first row, so the pointer steps by rows.
+func (arr *ArrayObject) Decay() *PointerObject {
+	if len(arr.Vals) > 0 {
+		switch elem := arr.Vals[0].(type) {
+		case *ArrayObject:
+			row := elem.Decay()
+			return &PointerObject{Addr: arr.Addr, DataType: row.DataType, Depth: row.Depth, Struct: row.Struct, Dims: append([]int{elem.Length}, row.Dims...)}
+		case *PointerObject:
+			return &PointerObject{Addr: arr.Addr, DataType: elem.DataType, Depth: elem.Depth + 1, Struct: elem.Struct}
+		case *StructObject:
+			return &PointerObject{Addr: arr.Addr, DataType: STRUCT_OBJ, Depth: 1, Struct: elem.Def}
+		}
+	}
+	return &PointerObject{Addr: arr.Addr, DataType: arr.DataType, Depth: 1}
+}
+
+func GetArrayObject(dataType ObjType, length int, vals []Object, defaultVal Object) Object {
+	for len(vals) < length {
+		vals = append(vals, Copy(defaultVal))
+	}
 	return &ArrayObject{
 		DataType: dataType,
 		Length:   length,
 		Vals:     vals,
 	}
 }
+
+// Pointer Object
+type PointerObject struct {
+	Addr     int64
+	DataType ObjType
+	Depth    int
+	// Struct is the definition of the pointed struct type, nil for pointers
+	// to scalars.
+	Struct *StructType
+	// Dims are the lengths of the row a pointer to a row of a
+	// multi-dimensional array points to, the pointer without them is the
+	// one to the elements of the row.
+	Dims []int
+}
+
+func (p *PointerObject) Type() ObjType {
+	return POINTER_OBJ
+}
+
+func (p *PointerObject) String() string {
+	return fmt.Sprintf("0x%x", p.Addr)
+}
+
+// Row returns the pointer to the first element of the row p points to,
+// which is what the row decays to once dereferenced.
+func (p *PointerObject) Row() *PointerObject {
+	return &PointerObject{Addr: p.Addr, DataType: p.DataType, Depth: p.Depth, Struct: p.Struct, Dims: p.Dims[1:]}
+}
+
+// IsRow reports whether p points to a row of a multi-dimensional array.
+func (p *PointerObject) IsRow() bool {
+	return len(p.Dims) > 0
+}
+
+// GetPointerObject returns a pointer to val, which is stored at addr.
+func GetPointerObject(addr int64, val Object) *PointerObject {
+	switch val := val.(type) {
+	case *PointerObject:
+		return &PointerObject{Addr: addr, DataType: val.DataType, Depth: val.Depth + 1, Struct: val.Struct}
+	case *StructObject:
+		return &PointerObject{Addr: addr, DataType: STRUCT_OBJ, Depth: 1, Struct: val.Def}
+	case *ArrayObject:
+		ptr := val.Decay()
+		ptr.Addr = addr
+		return ptr
+	default:
+		return &PointerObject{Addr: addr, DataType: val.Type(), Depth: 1}
+	}
+}
+
+// PointeeType is the type of the object the pointer refers to.
+func (p *PointerObject) PointeeType() ObjType {
+	if p.IsRow() {
+		return ARRAY_OBJ
+	}
+	if p.Depth > 1 {
+		return POINTER_OBJ
+	}
+	return p.DataType
+}
+
+// ElemSize is the number of bytes the pointer moves by for each step of
+// pointer arithmetic.
+func (p *PointerObject) ElemSize() int64 {
+	if p.IsRow() {
+		return int64(p.Dims[0]) * p.Row().ElemSize()
+	}
+	if p.Depth == 1 && p.Struct != nil {
+		return p.Struct.Size()
+	}
+	return SizeOfType(p.PointeeType())
+}
+
+// Offset returns the pointer moved by n elements.
+func (p *PointerObject) Offset(n int64) *PointerObject {
+	return &PointerObject{Addr: p.Addr + n*p.ElemSize(), DataType: p.DataType, Depth: p.Depth, Struct: p.Struct, Dims: p.Dims}
+}
+
+func (p *PointerObject) IsNull() bool {
+	return p.Addr == 0
+}
+
+func (p *PointerObject) IsVoid() bool {
+	return p.DataType == NULL_OBJ && p.Depth == 1 && !p.IsRow()
+}
+
+// SamePointee reports whether p and q point to objects of the same type.
+func (p *PointerObject) SamePointee(q *PointerObject) bool {
+	return p.DataType == q.DataType && p.Depth == q.Depth && p.Struct == q.Struct && slices.Equal(p.Dims, q.Dims)
+}
+
+// SameType reports whether val can be stored through the pointer.
+func (p *PointerObject) SameType(val Object) bool {
+	if p.IsRow() {
+		// rows are arrays, which are not assignable
+		return false
+	}
+	if p.Depth == 1 {
+		if st, ok := val.(*StructObject); ok {
+			return st.Def == p.Struct
+		}
+		return val.Type() == p.DataType
+	}
+	ptr, ok := val.(*PointerObject)
+	return ok && !ptr.IsRow() && ptr.DataType == p.DataType && ptr.Depth == p.Depth-1 && ptr.Struct == p.Struct
+}
@@ -0,0 +1,289 @@
+package obj
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+	"strings"
+)
+
+// StructType is the definition of a struct or union tag.
+type StructType struct {
+	Name   string
+	Union  bool
+	Fields []string
+	// Zero holds the default value of every member, new values of the
+	// type are copies of it.
+	Zero []Object
+}
+
+func (st *StructType) New() *StructObject {
+	vals := make([]Object, len(st.Zero))
+	for i, val := range st.Zero {
+		vals[i] = Copy(val)
+	}
+	obj := &StructObject{Def: st, Vals: vals}
+	if st.Union {
+		obj.bytes = make([]byte, st.Size())
+	}
+	return obj
+}
+
+func (st *StructType) FieldIndex(name string) int {
+	for i, field := range st.Fields {
+		if field == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (st *StructType) Size() int64 {
+	var size int64
+	for _, val := range st.Zero {
+		if st.Union {
+			size = max(size, SizeOf(val))
+		} else {
+			size += SizeOf(val)
+		}
+	}
+	return size
+}
+
+func (st *StructType) String() string {
+	if st.Union {
+		return "union " + st.Name
+	}
+	return "struct " + st.Name
+}
+
+// Struct Object, also used for unions
+type StructObject struct {
+	Def  *StructType
+	Vals []Object
+	Addr int64
+	// bytes is the storage the members of a union share, every member is
+	// decoded from it again whenever one of them is written.
+	bytes []byte
+}
+
+func (s *StructObject) Type() ObjType {
+	return STRUCT_OBJ
+}
+
+func (s *StructObject) String() string {
+	var str strings.Builder
+	str.WriteString("{")
+	for i, val := range s.Vals {
+		str.WriteString("." + s.Def.Fields[i] + " = " + val.String())
+		if i < len(s.Vals)-1 {
+			str.WriteString(", ")
+		}
+	}
+	str.WriteString("}")
+	return str.String()
+}
+
+// Offset is the distance in bytes of a member from the start of the struct,
+// union members all start at the beginning.
+func (s *StructObject) Offset(index int) int64 {
+	if s.Def.Union {
+		return 0
+	}
+	var offset int64
+	for _, val := range s.Vals[:index] {
+		offset += SizeOf(val)
+	}
+	return offset
+}
+
+// SetField stores val in a member. Writing a union member rewrites the
+// bytes of the union and the other members are decoded from them again.
+func (s *StructObject) SetField(index int, val Object) {
+	if isAggregate(s.Vals[index]) {
+		Overwrite(s.Vals[index], val)
+	} else {
+		s.Vals[index] = val
+	}
+	if s.Def.Union {
+		putBytes(s.bytes, 0, s.Vals[index])
+		s.decode(index)
+	}
+}
+
+// decode reads every member of the union but the one at skip from its
+// bytes, aggregates are updated in place so that pointers into them stay
+// valid.
+func (s *StructObject) decode(skip int) {
+	for i, val := range s.Vals {
+		if i != skip {
+			s.Vals[i] = readBytes(s.bytes, 0, val)
+		}
+	}
+}
+
+// load reads the bytes of the union at offset as a value of the type of
+// like.
+func (s *StructObject) load(offset int64, like Object) Object {
+	return readBytes(s.bytes, offset, Copy(like))
+}
+
+// store writes the bytes of val to the union at offset.
+func (s *StructObject) store(offset int64, val Object) {
+	putBytes(s.bytes, offset, val)
+	s.decode(-1)
+}
+
+// zeroPointee returns the zero value of the scalar or pointer ptr points to.
+func zeroPointee(ptr *PointerObject) Object {
+	if ptr.Depth > 1 {
+		return &PointerObject{DataType: ptr.DataType, Depth: ptr.Depth - 1, Struct: ptr.Struct}
+	}
+	switch ptr.DataType {
+	case INTEGER_OBJ:
+		return &IntegerObject{}
+	case FLOAT_OBJ:
+		return &FloatObject{}
+	case BOOLEAN_OBJ:
+		return GetBoolean(false)
+	default:
+		return &CharObject{}
+	}
+}
+
+func isAggregate(val Object) bool {
+	switch val.(type) {
+	case *ArrayObject, *StructObject:
+		return true
+	default:
+		return false
+	}
+}
+
+// Copy returns a deep copy of arrays and structs, scalars are immutable and
+// returned as is.
+func Copy(val Object) Object {
+	switch val := val.(type) {
+	case *ArrayObject:
+		return &ArrayObject{DataType: val.DataType, Length: val.Length, Vals: copyVals(val.Vals)}
+	case *StructObject:
+		return &StructObject{Def: val.Def, Vals: copyVals(val.Vals), bytes: slices.Clone(val.bytes)}
+	default:
+		return val
+	}
+}
+
+func copyVals(vals []Object) []Object {
+	copied := make([]Object, len(vals))
+	for i, val := range vals {
+		copied[i] = Copy(val)
+	}
+	return copied
+}
+
+// Overwrite copies the contents of src into the aggregate dst in place, so
+// every address inside dst stays valid.
+func Overwrite(dst Object, src Object) {
+	var dstVals, srcVals []Object
+	switch dst := dst.(type) {
+	case *ArrayObject:
+		dstVals, srcVals = dst.Vals, src.(*ArrayObject).Vals
+	case *StructObject:
+		dstVals, srcVals = dst.Vals, src.(*StructObject).Vals
+		copy(dst.bytes, src.(*StructObject).bytes)
+	default:
+		return
+	}
+	for i := range dstVals {
+		if isAggregate(dstVals[i]) {
+			Overwrite(dstVals[i], srcVals[i])
+		} else {
+			dstVals[i] = srcVals[i]
+		}
+	}
+}
+
+// putBytes writes the bytes of val to buf at offset, little-endian like
+// the machines C usually runs on. Bytes past the end of buf are dropped.
+// Strings have no bytes in this memory and are left out.
+func putBytes(buf []byte, offset int64, val Object) {
+	var scalar [8]byte
+	var size int
+	switch val := val.(type) {
+	case *IntegerObject:
+		binary.LittleEndian.PutUint32(scalar[:], uint32(val.Value))
+		size = 4
+	case *CharObject:
+		scalar[0], size = val.Value, 1
+	case *BooleanObject:
+		if val.Value {
+			scalar[0] = 1
+		}
+		size = 1
+	case *FloatObject:
+		binary.LittleEndian.PutUint64(scalar[:], math.Float64bits(val.Value))
+		size = 8
+	case *PointerObject:
+		binary.LittleEndian.PutUint64(scalar[:], uint64(val.Addr))
+		size = 8
+	case *ArrayObject:
+		elemSize := val.ElemSize()
+		for i, elem := range val.Vals {
+			putBytes(buf, offset+int64(i)*elemSize, elem)
+		}
+	case *StructObject:
+		if val.Def.Union {
+			if offset < int64(len(buf)) {
+				copy(buf[offset:], val.bytes)
+			}
+			return
+		}
+		for i, field := range val.Vals {
+			putBytes(buf, offset+val.Offset(i), field)
+		}
+	}
+	if offset < int64(len(buf)) {
+		copy(buf[offset:], scalar[:size])
+	}
+}
+
+// readBytes reads the bytes of buf at offset as a value of the type of
+// like, the way a union member is read after another member was written.
+// Aggregates are overwritten in place and bytes past the end of buf read
+// as zero.
+func readBytes(buf []byte, offset int64, like Object) Object {
+	var scalar [8]byte
+	if offset < int64(len(buf)) {
+		copy(scalar[:], buf[offset:])
+	}
+	switch like := like.(type) {
+	case *IntegerObject:
+		return &IntegerObject{Value: int64(int32(binary.LittleEndian.Uint32(scalar[:])))}
+	case *CharObject:
+		return &CharObject{Value: scalar[0]}
+	case *BooleanObject:
+		return GetBoolean(scalar[0] != 0)
+	case *FloatObject:
+		return &FloatObject{Value: math.Float64frombits(binary.LittleEndian.Uint64(scalar[:]))}
+	case *PointerObject:
+		return &PointerObject{Addr: int64(binary.LittleEndian.Uint64(scalar[:])), DataType: like.DataType, Depth: like.Depth, Struct: like.Struct, Dims: like.Dims}
+	case *ArrayObject:
+		elemSize := like.ElemSize()
+		for i, elem := range like.Vals {
+			like.Vals[i] = readBytes(buf, offset+int64(i)*elemSize, elem)
+		}
+	case *StructObject:
+		if like.Def.Union {
+			clear(like.bytes)
+			if offset < int64(len(buf)) {
+				copy(like.bytes, buf[offset:])
+			}
+			like.decode(-1)
+			return like
+		}
+		for i, field := range like.Vals {
+			like.Vals[i] = readBytes(buf, offset+like.Offset(i), field)
+		}
+	}
+	return like
+}
@@ -0,0 +1,162 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/mohamedirfanam/cynterpreter/eval/obj"
+	"github.com/mohamedirfanam/cynterpreter/lexer/token"
+	"github.com/mohamedirfanam/cynterpreter/parser/ast"
+)
+
+func evalAddressOf(exp ast.Expression, env *obj.Environment) obj.Object {
+	switch exp := exp.(type) {
+	case *ast.IdentifierExpression:
+		addr, ok := env.GetAddr(exp.Value)
+		if !ok {
+			return obj.NewError(fmt.Errorf("variable error: variable %s not declared in this scope", exp))
+		}
+		val, _ := env.GetVar(exp.Value)
+		return obj.GetPointerObject(addr, val)
+	case *ast.ArrayExpression:
+		container, index, errObj := evalArrayOperand(exp, env)
+		if errObj != nil {
+			return errObj
+		}
+		ptr, errObj := evalElementAddress(exp, container, index)
+		if errObj != nil {
+			return errObj
+		}
+		return ptr
+	case *ast.DereferenceExpression:
+		ptr, errObj := evalPointerOperand(exp.Exp, env)
+		if errObj != nil {
+			return errObj
+		}
+		return ptr
+	case *ast.MemberExpression:
+		st, index, errObj := evalMemberOperand(exp, env)
+		if errObj != nil {
+			return errObj
+		}
+		return obj.GetPointerObject(st.Addr+st.Offset(index), st.Vals[index])
+	}
+	return obj.NewError(fmt.Errorf("operator error: cannot take the address of %s, not an lvalue", exp))
+}
+
+// evalElementAddress returns a pointer to the element index of container,
+// which the array expression exp subscripts.
+func evalElementAddress(exp *ast.ArrayExpression, container obj.Object, index int) (*obj.PointerObject, obj.Object) {
+	// the address one past the last element is still a valid pointer
+	if errObj := checkIndex(exp, container, index, true); errObj != nil {
+		return nil, errObj
+	}
+	switch arr := container.(type) {
+	case *obj.ArrayObject:
+		ptr := arr.Decay()
+		ptr.Addr = arr.Addr + int64(index)*arr.ElemSize()
+		return ptr, nil
+	case *obj.PointerObject:
+		return arr.Offset(int64(index)), nil
+	}
+	return nil, obj.NewError(fmt.Errorf("operator error: cannot take the address of an element of %s", container.Type()))
+}
+
+// checkIndex reports an index outside of the array or string container that
+// exp subscripts, naming it in the error.
+func checkIndex(exp *ast.ArrayExpression, container obj.Object, index int, end bool) obj.Object {
+	if err := obj.CheckIndex(container, exp.Left.String(), index, end); err != nil {
+		return obj.NewError(err)
+	}
+	return nil
+}
+
+func evalDereferenceExpression(de *ast.DereferenceExpression, env *obj.Environment) obj.Object {
+	ptr, errObj := evalPointerOperand(de.Exp, env)
+	if errObj != nil {
+		return errObj
+	}
+	val, err := env.Memory().Deref(ptr)
+	if err != nil {
+		return obj.NewError(err)
+	}
+	return val
+}
+
+// evalPointerOperand evaluates the operand of a dereference, arrays decay
+// to a pointer to their first element.
+func evalPointerOperand(exp ast.Expression, env *obj.Environment) (*obj.PointerObject, obj.Object) {
+	val := Eval(exp, env)
+	if val.Type() == obj.ERROR_OBJ {
+		return nil, val
+	}
+	ptr, ok := toPointer(val)
+	if !ok {
+		return nil, obj.NewError(fmt.Errorf("type error: Invalid operand type for dereference operator, expected pointer but got %s", val.Type()))
+	}
+	if ptr.IsVoid() {
+		return nil, obj.NewError(fmt.Errorf("type error: cannot dereference a void pointer"))
+	}
+	return ptr, nil
+}
+
+func toPointer(val obj.Object) (*obj.PointerObject, bool) {
+	switch val := val.(type) {
+	case *obj.PointerObject:
+		return val, true
+	case *obj.ArrayObject:
+		return val.Decay(), true
+	default:
+		return nil, false
+	}
+}
+
+func isPointerOperand(val obj.Object) bool {
+	_, ok := toPointer(val)
+	return ok
+}
+
+func evalPointerInfixExpression(op token.TokenType, leftVal obj.Object, rightVal obj.Object) obj.Object {
+	lPtr, lIsPtr := toPointer(leftVal)
+	rPtr, rIsPtr := toPointer(rightVal)
+	lInt, lIsInt := leftVal.(*obj.IntegerObject)
+	rInt, rIsInt := rightVal.(*obj.IntegerObject)
+
+	switch {
+	case op == token.PLUS && lIsPtr && rIsInt:
+		return lPtr.Offset(rInt.Value)
+	case op == token.PLUS && lIsInt && rIsPtr:
+		return rPtr.Offset(lInt.Value)
+	case op == token.MINUS && lIsPtr && rIsInt:
+		return lPtr.Offset(-rInt.Value)
+	case op == token.MINUS && lIsPtr && rIsPtr:
+		if !lPtr.SamePointee(rPtr) {
+			return obj.NewError(fmt.Errorf("type error: Invalid operand types for subtraction operator, pointers to different types"))
+		}
+		return &obj.IntegerObject{Value: (lPtr.Addr - rPtr.Addr) / lPtr.ElemSize()}
+	case lIsPtr && rIsPtr:
+		return comparePointers(op, lPtr.Addr, rPtr.Addr)
+	case lIsPtr && rIsInt && rInt.Value == 0:
+		return comparePointers(op, lPtr.Addr, 0)
+	case lIsInt && rIsPtr && lInt.Value == 0:
+		return comparePointers(op, 0, rPtr.Addr)
+	}
+	return obj.NewError(fmt.Errorf("type error: Invalid operand types for operator '%s', got %s and %s", op, leftVal.Type(), rightVal.Type()))
+}
+
+func comparePointers(op token.TokenType, lAddr int64, rAddr int64) obj.Object {
+	switch op {
+	case token.EQ:
+		return obj.GetBoolean(lAddr == rAddr)
+	case token.NE:
+		return obj.GetBoolean(lAddr != rAddr)
+	case token.LT:
+		return obj.GetBoolean(lAddr < rAddr)
+	case token.LE:
+		return obj.GetBoolean(lAddr <= rAddr)
+	case token.GT:
+		return obj.GetBoolean(lAddr > rAddr)
+	case token.GE:
+		return obj.GetBoolean(lAddr >= rAddr)
+	}
+	return obj.NewError(fmt.Errorf("operator error: Unsupported operator '%s' for pointer operands", op))
+}
@@ -4,16 +4,21 @@ import (
 	"fmt"
 
 	"github.com/mohamedirfanam/cynterpreter/eval/obj"
+	"github.com/mohamedirfanam/cynterpreter/lexer/token"
 	"github.com/mohamedirfanam/cynterpreter/parser/ast"
 )
 
 func evalIfStatement(ifs *ast.IfStatement, env *obj.Environment) obj.Object {
 	result := Eval(ifs.Condition, env)
-	condition := IsTrue(result)
-	if condition {
-		return evalBlock(ifs.Block, env)
-	} else if !condition && ifs.ElseBlock != nil {
-		return evalBlock(ifs.ElseBlock, env)
+	if result.Type() == obj.ERROR_OBJ {
+		return result
+	}
+	if IsTrue(result) {
+		return evalScopedBlock(ifs.Block, env)
+	} else if ifs.ElseIf != nil {
+		return evalIfStatement(ifs.ElseIf, env)
+	} else if ifs.ElseBlock != nil {
+		return evalScopedBlock(ifs.ElseBlock, env)
 	}
 	return obj.NULL
 }
@@ -22,7 +27,7 @@ func evalBlock(blk *ast.Block, env *obj.Environment) obj.Object {
 	var results []obj.Object
 	for _, stmnt := range blk.Statements {
 		result := Eval(stmnt, env)
-		if result.Type() == obj.ERROR_OBJ || result.Type() == obj.RETURN_OBJ {
+		if isControlTransfer(result) {
 			return result
 		}
 		results = append(results, result)
@@ -30,71 +35,227 @@ func evalBlock(blk *ast.Block, env *obj.Environment) obj.Object {
 	return &obj.ResultsObject{Results: results}
 }
 
+// evalScopedBlock runs blk in a scope of its own, the variables it declares
+// are freed when it exits.
+func evalScopedBlock(blk *ast.Block, env *obj.Environment) obj.Object {
+	scope := env.NewScope()
+	defer scope.Release()
+	return evalBlock(blk, scope)
+}
+
 func evalDeclarationStatement(ls *ast.DeclarationStatement, env *obj.Environment) obj.Object {
-	if _, ok := env.GetVar(ls.Identifier.Value); ok {
-		return obj.NewError(fmt.Errorf("variable redeclaration error: variable %s already declared before", ls.Identifier))
+	for _, d := range ls.Declarators {
+		if result := evalDeclarator(ls, d, env); result.Type() == obj.ERROR_OBJ {
+			return result
+		}
+	}
+	return obj.NULL
+}
+
+// evalDeclarator declares one of the variables of a declaration statement,
+// declarators are evaluated left to right so an initializer can use the
+// variables declared before it.
+func evalDeclarator(ls *ast.DeclarationStatement, d *ast.Declarator, env *obj.Environment) obj.Object {
+	if fl, ok := d.Literal.(*ast.FunctionLiteral); ok {
+		return evalFunctionDeclarator(ls, d, fl, env)
+	}
+	if env.DeclaredInScope(d.Identifier.Value) {
+		return obj.NewError(fmt.Errorf("variable redeclaration error: variable %s already declared before", d.Identifier))
+	}
+	defaultVal := getDefaultVal(ls.Type, ls.TypeName, d.Pointers, env)
+	if defaultVal.Type() == obj.ERROR_OBJ {
+		return defaultVal
+	}
+	if d.Literal == nil {
+		env.DeclareVar(d.Identifier.Value, defaultVal)
+		return obj.NULL
 	}
-	if ls.Literal == nil {
-		env.SetVar(ls.Identifier.Value, obj.GetDefaultVal(ls.Type))
+	if arr, ok := d.Literal.(*ast.ArrayDeclaration); ok {
+		elem := zeroRow(defaultVal, arr.Dims)
+		vals, errObj := evalArrayValExpressions(arr.Literal, env, elem)
+		if errObj != nil {
+			if elem.Type() == obj.ARRAY_OBJ {
+				// the error of the row names what is wrong with it
+				return errObj
+			}
+			return obj.NewError(fmt.Errorf("type error: all array values are not of the type %s", defaultVal.Type()))
+		}
+		arrObject := obj.GetArrayObject(defaultVal.Type(), arr.Length, vals, elem)
+		env.DeclareVar(d.Identifier.Value, arrObject)
 		return obj.NULL
 	}
-	val := Eval(ls.Literal, env)
+	val := evalInitializer(defaultVal, d.Literal, env)
 	if val.Type() == obj.ERROR_OBJ {
 		return val
 	}
-	if fl, ok := ls.Literal.(*ast.FunctionLiteral); ok {
-		returnType := obj.GetObjectType(ls.Type)
-		functionObj := obj.GetFunctionObject(returnType, fl)
-		env.SetVar(ls.Identifier.Value, functionObj)
+	env.DeclareVar(d.Identifier.Value, val)
+	return obj.NULL
+}
+
+// evalFunctionDeclarator records a function prototype or definition in the
+// scope. A function may be declared any number of times and defined once,
+// every declaration has to agree with the earlier ones.
+func evalFunctionDeclarator(ls *ast.DeclarationStatement, d *ast.Declarator, fl *ast.FunctionLiteral, env *obj.Environment) obj.Object {
+	returnType := obj.GetObjectType(ls.Type)
+	if d.Pointers > 0 {
+		returnType = obj.POINTER_OBJ
+	}
+	functionObj := obj.GetFunctionObject(returnType, fl).(*obj.FunctionObject)
+	if !env.DeclaredInScope(d.Identifier.Value) {
+		env.DeclareVar(d.Identifier.Value, functionObj)
 		return obj.NULL
-	} else if arr, ok := ls.Literal.(*ast.ArrayDeclaration); ok {
-		arrType := obj.GetObjectType(ls.Type)
-		vals, ok := evalArrayValExpressions(arr.Literal, env, arrType)
-		if !ok {
-			return obj.NewError(fmt.Errorf("type error: all array values are not of the type %s", arrType))
-		}
-		arrObject := obj.GetArrayObject(arrType, arr.Length, vals)
-		env.SetVar(ls.Identifier.Value, arrObject)
+	}
+	existing, _ := env.GetVar(d.Identifier.Value)
+	declared, ok := existing.(*obj.FunctionObject)
+	if !ok {
+		return obj.NewError(fmt.Errorf("variable redeclaration error: variable %s already declared before", d.Identifier))
+	}
+	if err := checkFunctionSignature(d.Identifier.Value, declared, functionObj); err != nil {
+		return obj.NewError(err)
+	}
+	if fl.Block == nil {
 		return obj.NULL
+	}
+	if declared.Block != nil {
+		return obj.NewError(fmt.Errorf("function redefinition error: function %s already defined before", d.Identifier))
+	}
+	env.SetVar(d.Identifier.Value, functionObj)
+	return obj.NULL
+}
 
-	} else if obj.GetObjectType(ls.Type) == val.Type() {
-		env.SetVar(ls.Identifier.Value, val)
-		return obj.NULL
+// checkFunctionSignature reports how the declaration fn of a function
+// conflicts with its earlier declaration declared.
+func checkFunctionSignature(name string, declared *obj.FunctionObject, fn *obj.FunctionObject) error {
+	if fn.ReturnType != declared.ReturnType {
+		return fmt.Errorf("conflicting types for function %s, return type %s does not match the earlier declaration's %s", name, fn.ReturnType, declared.ReturnType)
 	}
-	return obj.NewError(fmt.Errorf("type error: invalid declaration type cannot assign %s to %s", val.Type(), ls.Type))
+	if len(fn.Params) != len(declared.Params) || fn.Variadic != declared.Variadic {
+		return fmt.Errorf("conflicting types for function %s, %s parameters do not match the earlier declaration's %s", name, arityString(fn), arityString(declared))
+	}
+	for i, param := range fn.Params {
+		if param.TypeString() != declared.Params[i].TypeString() {
+			return fmt.Errorf("conflicting types for function %s, parameter %d is %s but %s in the earlier declaration", name, i+1, param.TypeString(), declared.Params[i].TypeString())
+		}
+	}
+	return nil
+}
+
+func arityString(fn *obj.FunctionObject) string {
+	if fn.Variadic {
+		return fmt.Sprintf("%d+", len(fn.Params))
+	}
+	return fmt.Sprint(len(fn.Params))
 }
 
 func evalAssignmentStatement(ls *ast.AssignmentStatement, env *obj.Environment) obj.Object {
-	val := Eval(ls.Literal, env)
+	var result obj.Object
+	if op, ok := token.AssignmentOpMap[ls.Op.TokenType]; ok {
+		op.Pos = ls.Op.Pos
+		result = evalCompoundAssignment(op, ls.Identifier, ls.Literal, env)
+	} else {
+		result = evalAssignment(ls.Identifier, ls.Literal, env)
+	}
+	if result.Type() == obj.ERROR_OBJ {
+		return result
+	}
+	return obj.NULL
+}
+
+// evalAssignment stores the value of exp in target and returns the value
+// stored, which is what an assignment expression evaluates to.
+func evalAssignment(target ast.IdentifierNode, exp ast.Expression, env *obj.Environment) obj.Object {
+	val := Eval(exp, env)
 	if val.Type() == obj.ERROR_OBJ {
 		return val
 	}
-	switch ident := ls.Identifier.(type) {
+	switch ident := target.(type) {
 	case *ast.IdentifierExpression:
 		varObj, ok := env.GetVar(ident.Value)
 		if !ok {
-			return obj.NewError(fmt.Errorf("variable not declared: variable %s not declared before, for assigment", ls.Identifier))
+			return obj.NewError(fmt.Errorf("variable not declared: variable %s not declared before, for assigment", target))
 		}
-		if varObj.Type() != val.Type() {
-			return obj.NewError(fmt.Errorf("type error: invalid assigment type cannot assign %s to %s", val.Type(), varObj.Type()))
+		if varObj.Type() == obj.ARRAY_OBJ {
+			return obj.NewError(fmt.Errorf("type error: array %s is not assignable", target))
 		}
-		env.SetVar(ident.Value, val)
-	case *ast.ArrayExpression:
-		_, ok := env.GetVar(ident.Identifer.Value)
+		converted, ok := convertForAssignment(varObj, val)
 		if !ok {
-			return obj.NewError(fmt.Errorf("variable not declared: variable %s not declared before, for assigment", ls.Identifier))
+			return obj.NewError(fmt.Errorf("type error: invalid assigment type cannot assign %s to %s", typeString(val), typeString(varObj)))
 		}
-		expObj := Eval(ident.Index, env)
-		expInt, ok := expObj.(*obj.IntegerObject)
-		if !ok {
-			return obj.NewError(fmt.Errorf("invalid index type, expected an integer, got %s", expObj.Type()))
+		env.SetVar(ident.Value, converted)
+		return converted
+	case *ast.ArrayExpression:
+		if name, ok := ident.Left.(*ast.IdentifierExpression); ok {
+			if _, ok := env.GetVar(name.Value); !ok {
+				return obj.NewError(fmt.Errorf("variable not declared: variable %s not declared before, for assigment", target))
+			}
+		}
+		container, index, errObj := evalArrayOperand(ident, env)
+		if errObj != nil {
+			return errObj
+		}
+		if errObj := checkIndex(ident, container, index, false); errObj != nil {
+			return errObj
+		}
+		if elem, err := env.Index(container, index); err == nil && elem.Type() == obj.ARRAY_OBJ {
+			return obj.NewError(fmt.Errorf("type error: array %s is not assignable", target))
 		}
-		err := env.SetIndexVar(ident.Identifer.Value, int(expInt.Value), val)
+		if err := env.SetIndex(container, index, val); err != nil {
+			return obj.NewError(err)
+		}
+		return val
+	case *ast.DereferenceExpression:
+		ptr, errObj := evalPointerOperand(ident.Exp, env)
+		if errObj != nil {
+			return errObj
+		}
+		if ptr.IsRow() {
+			return obj.NewError(fmt.Errorf("type error: array %s is not assignable", target))
+		}
+		oldVal, err := env.Memory().Deref(ptr)
 		if err != nil {
 			return obj.NewError(err)
 		}
+		converted, ok := convertForAssignment(oldVal, val)
+		if !ok {
+			return obj.NewError(fmt.Errorf("type error: invalid assigment type cannot assign %s to %s", typeString(val), typeString(oldVal)))
+		}
+		if err := env.Memory().Assign(ptr, converted); err != nil {
+			return obj.NewError(err)
+		}
+		return converted
+	case *ast.MemberExpression:
+		return evalMemberAssignment(ident, val, env)
 	}
-	return obj.NULL
+	return obj.NewError(fmt.Errorf("type error: %s is not assignable", target))
+}
+
+// evalCompoundAssignment stores target op exp in target and returns it, the
+// target is resolved once so that a[i++] += 1 increments i once.
+func evalCompoundAssignment(op token.Token, target ast.IdentifierNode, exp ast.Expression, env *obj.Environment) obj.Object {
+	lv, errObj := evalLvalue(op, target, env)
+	if errObj != nil {
+		return errObj
+	}
+	oldVal, err := lv.load(env)
+	if err != nil {
+		return obj.NewError(err)
+	}
+	val := Eval(exp, env)
+	if val.Type() == obj.ERROR_OBJ {
+		return val
+	}
+	result := evalBinaryOperator(op, oldVal, val)
+	if result.Type() == obj.ERROR_OBJ {
+		return result
+	}
+	converted, ok := convertForAssignment(oldVal, result)
+	if !ok {
+		return obj.NewError(fmt.Errorf("type error: invalid assigment type cannot assign %s to %s", typeString(result), typeString(oldVal)))
+	}
+	if err := lv.store(env, converted); err != nil {
+		return obj.NewError(err)
+	}
+	return converted
 }
 
 func evalReturnStatement(rs *ast.ReturnStatement, env *obj.Environment) obj.Object {
@@ -104,49 +265,160 @@ func evalReturnStatement(rs *ast.ReturnStatement, env *obj.Environment) obj.Obje
 		}
 	}
 	expr := Eval(rs.Expression, env)
+	if expr.Type() == obj.ERROR_OBJ {
+		return expr
+	}
 	return &obj.ReturnObject{
 		Return: expr,
 	}
 }
 
 func evalWhileLoop(wl *ast.WhileStatement, env *obj.Environment) obj.Object {
-	conditionVal := Eval(wl.Condition, env)
-	condition := IsTrue(conditionVal)
 	results := &obj.ResultsObject{}
-	for condition {
-		newEnv := env.CopyEnv()
-		result := evalBlock(wl.Block, newEnv)
-		env.UpdateVals(newEnv)
-		if resultVal, ok := result.(*obj.ResultsObject); ok {
-			results.Results = append(results.Results, resultVal.Results...)
-		} else if result.Type() == obj.RETURN_OBJ || result.Type() == obj.ERROR_OBJ {
+	for {
+		conditionVal := Eval(wl.Condition, env)
+		if conditionVal.Type() == obj.ERROR_OBJ {
+			return conditionVal
+		}
+		if !IsTrue(conditionVal) {
+			return results
+		}
+		if result := evalLoopBody(wl.Block, env, results); result != nil {
 			return result
 		}
-		conditionVal = Eval(wl.Condition, env)
-		condition = IsTrue(conditionVal)
 	}
-	return results
+}
+
+func evalDoWhileLoop(dw *ast.DoWhileStatement, env *obj.Environment) obj.Object {
+	results := &obj.ResultsObject{}
+	for {
+		if result := evalLoopBody(dw.Block, env, results); result != nil {
+			return result
+		}
+		conditionVal := Eval(dw.Condition, env)
+		if conditionVal.Type() == obj.ERROR_OBJ {
+			return conditionVal
+		}
+		if !IsTrue(conditionVal) {
+			return results
+		}
+	}
 }
 
 func evalForLoop(fl *ast.ForStatement, env *obj.Environment) obj.Object {
 	results := &obj.ResultsObject{}
-	dupEnv := env.CopyEnv()
-	Eval(fl.InitializationStatement, dupEnv)
-	conditionVal := Eval(fl.Condition, dupEnv)
-	condition := IsTrue(conditionVal)
-	for condition {
-		newEnv := dupEnv.CopyEnv()
-		result := evalBlock(fl.Block, newEnv)
-		dupEnv.UpdateVals(newEnv)
-		if resultVal, ok := result.(*obj.ResultsObject); ok {
-			results.Results = append(results.Results, resultVal.Results...)
-		} else if result.Type() == obj.RETURN_OBJ || result.Type() == obj.ERROR_OBJ {
+	// variables declared in the init clause live in a scope of their own
+	scope := env.NewScope()
+	defer scope.Release()
+	if fl.Init != nil {
+		if init := Eval(fl.Init, scope); init.Type() == obj.ERROR_OBJ {
+			return init
+		}
+	}
+	for {
+		if fl.Condition != nil {
+			conditionVal := Eval(fl.Condition, scope)
+			if conditionVal.Type() == obj.ERROR_OBJ {
+				return conditionVal
+			}
+			if !IsTrue(conditionVal) {
+				return results
+			}
+		}
+		if result := evalLoopBody(fl.Block, scope, results); result != nil {
 			return result
 		}
-		Eval(fl.Increment, dupEnv)
-		conditionVal = Eval(fl.Condition, dupEnv)
-		condition = IsTrue(conditionVal)
+		if fl.Increment != nil {
+			if increment := Eval(fl.Increment, scope); increment.Type() == obj.ERROR_OBJ {
+				return increment
+			}
+		}
+	}
+}
+
+// evalLoopBody runs one iteration of a loop body in its own scope and
+// collects its results. It returns nil while the loop goes on, results once
+// a break ends it, or the return or error that ended it.
+func evalLoopBody(blk *ast.Block, env *obj.Environment, results *obj.ResultsObject) obj.Object {
+	result := evalScopedBlock(blk, env)
+	switch result := result.(type) {
+	case *obj.ResultsObject:
+		results.Results = append(results.Results, result.Results...)
+	case *obj.BreakObject:
+		return results
+	case *obj.ContinueObject:
+	default:
+		return result
+	}
+	return nil
+}
+
+// isControlTransfer reports whether result leaves the current block early.
+func isControlTransfer(result obj.Object) bool {
+	switch result.Type() {
+	case obj.ERROR_OBJ, obj.RETURN_OBJ, obj.BREAK_OBJ, obj.CONTINUE_OBJ:
+		return true
+	default:
+		return false
+	}
+}
+
+func evalSwitchStatement(ss *ast.SwitchStatement, env *obj.Environment) obj.Object {
+	conditionVal := Eval(ss.Condition, env)
+	if conditionVal.Type() == obj.ERROR_OBJ {
+		return conditionVal
+	}
+	val, ok := integralValue(conditionVal)
+	if !ok {
+		return obj.NewError(fmt.Errorf("type error: switch quantity not an integer, got %s", conditionVal.Type()))
+	}
+	start := -1
+	for i, clause := range ss.Cases {
+		if clause.IsDefault() {
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+		if label, _ := integralValue(Eval(clause.Value, env)); label == val {
+			start = i
+			break
+		}
+	}
+	results := &obj.ResultsObject{}
+	if start == -1 {
+		return results
+	}
+	// execution falls through every clause after the matching one until a
+	// break is reached
+	scope := env.NewScope()
+	defer scope.Release()
+	for _, clause := range ss.Cases[start:] {
+		for _, stmnt := range clause.Statements {
+			result := Eval(stmnt, scope)
+			if resultVal, ok := result.(*obj.ResultsObject); ok {
+				results.Results = append(results.Results, resultVal.Results...)
+				continue
+			}
+			switch result.Type() {
+			case obj.BREAK_OBJ:
+				return results
+			case obj.RETURN_OBJ, obj.ERROR_OBJ, obj.CONTINUE_OBJ:
+				return result
+			}
+			results.Results = append(results.Results, result)
+		}
 	}
-	env.UpdateVals(dupEnv)
 	return results
 }
+
+func integralValue(val obj.Object) (int64, bool) {
+	switch val := val.(type) {
+	case *obj.IntegerObject:
+		return val.Value, true
+	case *obj.CharObject:
+		return int64(val.Value), true
+	default:
+		return 0, false
+	}
+}
@@ -1,6 +1,7 @@
 package eval
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/mohamedirfanam/cynterpreter/eval/obj"
@@ -37,6 +38,16 @@ func TestIfStatement(t *testing.T) {
 		// Single statement
 		{"if(true){999;}", []int{999}},
 		{"if(false){888;}else{777;}", []int{777}},
+		// Bodies without braces
+		{"if(true) 10;", []int{10}},
+		{"if(false) 10; else 20;", []int{20}},
+		{"if(false) 10; else {20;30;}", []int{20, 30}},
+		{"if(false) ;", []int{}},
+		// Else if chains
+		{"if(0){1;}else if(1){2;}else{3;}", []int{2}},
+		{"if(0) 1; else if(0) 2; else 3;", []int{3}},
+		{"if(0) 1; else if(0) 2;", []int{}},
+		{"if(1){1;}else if(1){2;}", []int{1}},
 	}
 	env := obj.NewEnv()
 	for i, tt := range tests {
@@ -73,6 +84,19 @@ func TestIfStatement(t *testing.T) {
 			testIntegerObject(t, object, tt.expected[j])
 		}
 	}
+
+	// an error in a condition is reported instead of taking the branch
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int a[5];\nif (a[5] == 99) { 1; }", "2:6: invalid index, index 5 out of bounds for array a of length 5"},
+		{"if (0) { 1; } else if (undefined) { 2; } else { 3; }", "1:24: variable error: variable undefined not declared in this scope"},
+		{"int f() { if (missing) { return 1; } return 0; }\nf();", "1:15: variable error: variable missing not declared in this scope"},
+	}
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
 }
 
 func TestDeclarationStatement(t *testing.T) {
@@ -175,6 +199,37 @@ func TestDeclarationStatement(t *testing.T) {
 	}
 }
 
+func TestMultipleDeclarators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int a, b = 2, c; a + b + c;", 2},
+		{"int n = 3, m = n + 1; m;", 4},
+		{"int a, c[3], d[] = {4, 5}; c[2] = 7; c[2] + d[1];", 12},
+		{"int a = 5, *p = &a, **pp = &p; **pp;", 5},
+		{"int s = 0; for (int i = 0, j = 10; i < j; i++, j--) { s++; } s;", 5},
+		{"struct P { int x, y; }; struct P s = {1, 2}, *ps = &s; ps->y;", 2},
+		{"struct P { int x, *p; }; struct P s; int v = 9; s.p = &v; *s.p;", 9},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int a, a;", "variable redeclaration error: variable a already declared before"},
+		{"int a = 1, b = \"x\";", "type error: invalid declaration type cannot assign STRING_OBJ to INTEGER_OBJ"},
+		{"struct P { int x, x; };", "member redeclaration error: member x already declared in struct P"},
+	}
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
 func TestAssignmentStatement(t *testing.T) {
 	tests := []struct {
 		declaration string
@@ -201,6 +256,11 @@ func TestAssignmentStatement(t *testing.T) {
 		{"string str = \"Hello\";", "str += \" World\";", "str", "Hello World"},
 		{"int neg = 5;", "neg -= 10;", "neg", int64(-5)},
 		{"float zero = 5.0;", "zero *= 0.0;", "zero", 0.0},
+		{"int m = 12;", "m &= 10;", "m", int64(8)},
+		{"int o = 12;", "o |= 3;", "o", int64(15)},
+		{"int e = 12;", "e ^= 10;", "e", int64(6)},
+		{"int l = 3;", "l <<= 4;", "l", int64(48)},
+		{"int r = -64;", "r >>= 3;", "r", int64(-8)},
 	}
 
 	for i, tt := range tests {
@@ -308,6 +368,55 @@ func TestAssignmentStatement(t *testing.T) {
 	}
 }
 
+func TestAssignmentExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int a; int b; a = b = 4; a + b;", 8},
+		{"int a = 1; int b = 2; a += b += 3; a * 10 + b;", 65},
+		{"int x; (x = 5) + 1;", 6},
+		{"int x = 0; int n = 0; while ((x += 3) < 10) { n++; } n;", 3},
+		{"int a; int b; (a = 1, b = 2, a + b);", 3},
+		{"int x = (1, 2, 3); x;", 3},
+		{"int i; int j; for (i = 0, j = 10; i < j; i++, j--) { } i * 100 + j;", 505},
+		{"int k = 0; for (;;) { if (++k == 4) { break; } } k;", 4},
+		{"int k = 0; for (; k < 3;) { k++; } k;", 3},
+		{"int arr[3]; arr[0] = arr[1] = arr[2] = 5; arr[0] + arr[1] + arr[2];", 15},
+		{"int x; int *p = &x; *p = 7; x;", 7},
+		{"int x; int *p = &x; (*p = 8) * 2;", 16},
+		{"struct S { int x; int y; }; struct S s; struct S *ps = &s; ps->y = s.x = 3; s.y;", 3},
+		{"struct S { int x; }; struct S s; struct S *ps = &s; (*ps).x = 6; s.x;", 6},
+		{"int a = 0; if ((a = 2)) { a++; } a;", 3},
+		{"int a[2]; int i = 0; a[i++] += 5; a[0] * 100 + a[1] * 10 + i;", 501},
+		{"int a[2]; int i = 0; (a[i++] += 5) * 10 + i;", 51},
+		{"int a[3] = {1, 2, 3}; int *p = a; p += 2; *p;", 3},
+		{"int x = 7; (x /= 2) * 10 + x;", 33},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int a; (a = 1.5) + 1;", "1:11: type error: invalid assigment type cannot assign FLOAT_OBJ to INTEGER_OBJ"},
+		{`int a; int b; a = b = "x";`, "1:21: type error: invalid assigment type cannot assign STRING_OBJ to INTEGER_OBJ"},
+		{"int a[2]; (a = 1);", "1:11: type error: array a is not assignable"},
+		{"int a[2]; a += 1;", "1:11: type error: array a is not assignable"},
+		{"int a[2]; int i = 0; a[i++] += undefined;", "1:32: variable error: variable undefined not declared in this scope"},
+		{"for (int i = 0; i < undefined; i++) { }", "1:21: variable error: variable undefined not declared in this scope"},
+		{"for (int i = 0; i < 3; i = undefined) { }", "1:28: variable error: variable undefined not declared in this scope"},
+		{"int *p; { int x = 1; p = &x; } (*p = 2) + 1;", "segmentation fault: invalid memory access"},
+		{"int *p; int x = (*p = 2);", "segmentation fault: null pointer dereference"},
+	}
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
 func TestVariableUsage(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -495,6 +604,84 @@ func TestFunctionDeclarationStatement(t *testing.T) {
 	}
 }
 
+func TestGlobalVariables(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int counter = 0; void bump() { counter++; } int main() { bump(); bump(); return counter; } main();", 2},
+		{"int g = 5; int read() { return g; } int main() { g = 7; return read(); } main();", 7},
+		{"int g = 5; int f(int g) { return g * 10; } int main() { return f(3) + g; } main();", 35},
+		{"int g = 5; int main() { int g = 1; g++; return g; } main(); g;", 5},
+		{"int g = 1; int read() { return g; } int main() { int g = 100; return read(); } main();", 1},
+		{"int a = 2; int b = a * 3; int main() { return b; } main();", 6},
+		{"int t[3] = {1, 2, 3}; int sum() { int s = 0; for (int i = 0; i < 3; i++) { s += t[i]; } return s; } int main() { t[0] = 10; return sum(); } main();", 15},
+		{"int g = 4; int *gp = &g; int main() { *gp = 9; return g; } main();", 9},
+		{"struct P { int x; }; struct P origin = {3}; int main() { origin.x++; return origin.x; } main();", 4},
+		{"int n = 0; int fib(int k) { n++; if (k < 2) { return k; } return fib(k - 1) + fib(k - 2); } int main() { fib(5); return n; } main();", 15},
+		{"int g = 0; int main() { for (int i = 0; i < 3; i++) { int g = i; } return g; } main();", 0},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int x = 1; int x = 2;", "1:12: variable redeclaration error: variable x already declared before"},
+		{"int x = y; int y = 1;", "1:9: variable error: variable y not declared in this scope"},
+		{"int f() { int local = 1; return 0; } int g() { return local; } f(); g();", "1:55: variable error: variable local not declared in this scope"},
+		{"int main() { int a = 1; int a = 2; return a; } main();", "1:25: variable redeclaration error: variable a already declared before"},
+	}
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestBlockScopes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int x = 1; { int x = 2; x++; } x;", 1},
+		{"int x = 1; { x = 5; } x;", 5},
+		{"int x = 1; { int y = 10; { int x = y + 1; y = x; } x = y; } x;", 11},
+		{"int x = 1; if (x) { int x = 7; x++; } x;", 1},
+		{"int x = 1; if (0) { x = 2; } else { int x = 3; x++; } x;", 1},
+		{"int s = 0; for (int i = 0; i < 3; i++) { int t = i * 2; s += t; } s;", 6},
+		{"int s = 0; int i = 0; while (i < 3) { int t = i; s += t; i++; } s;", 3},
+		{"int i = 42; for (int i = 0; i < 3; i++) { } i;", 42},
+		{"int x = 2; switch (x) { case 2: { int x = 9; } break; } x;", 2},
+		{"int f(int n) { int r = n; { int n = 100; r += n; } return r + n; } f(1);", 102},
+		{"int *p; int n = 0; for (int i = 0; i < 3; i++) { int x = i; if (i == 0) { p = &x; } if (p == &x) { n++; } } n;", 3},
+		{"int *p; int n = 0; int f() { int x; if (p == &x) { n++; } p = &x; return 0; } f(); f(); f(); n;", 2},
+		{"int f(int n) { int a[64]; a[63] = n; if (n == 0) { return 0; } return a[63] + f(n - 1); } int s = 0; for (int i = 0; i < 50; i++) { s += f(3); } s;", 300},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"{ int y = 1; } y;", "1:16: variable error: variable y not declared in this scope"},
+		{"if (1) { int y = 1; } y;", "1:23: variable error: variable y not declared in this scope"},
+		{"for (int i = 0; i < 3; i++) { } i;", "1:33: variable error: variable i not declared in this scope"},
+		{"int n = 0; while (n < 1) { int k = 1; n++; } k;", "1:46: variable error: variable k not declared in this scope"},
+		{"{ int a = 1; int a = 2; }", "1:14: variable redeclaration error: variable a already declared before"},
+		{"int f() { return caller; } int main() { int caller = 1; return f(); } main();", "1:18: variable error: variable caller not declared in this scope"},
+		{"int *f() { int x = 5; return &x; } int *p; p = f(); *p;", "segmentation fault: invalid memory access"},
+		{"int *p; { int x = 1; p = &x; } *p;", "segmentation fault: invalid memory access"},
+	}
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
 func TestReturnStatement(t *testing.T) {
 	input := `
 	return 1;
@@ -627,6 +814,342 @@ func TestFunctionCall(t *testing.T) {
 	}
 }
 
+func TestFunctionPrototypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{
+			input: `int twice(int);
+			int twice(int n){ return n * 2; }
+			twice(21);`,
+			expected: 42,
+		},
+		{
+			input: `int isEven(int);
+			int isOdd(int n);
+			int isEven(int n){ if (n == 0) { return 1; } return isOdd(n - 1); }
+			int isOdd(int n){ if (n == 0) { return 0; } return isEven(n - 1); }
+			isEven(10) * 10 + isOdd(7);`,
+			expected: 11,
+		},
+		{
+			input: `int sum(int *, int);
+			int sum(int *p, int n){ int s = 0; for (int i = 0; i < n; i++) { s += p[i]; } return s; }
+			int arr[3] = {1, 2, 3};
+			sum(&arr[0], 3);`,
+			expected: 6,
+		},
+		{
+			input: `int zero(void);
+			int zero(void);
+			int zero(){ return 0; }
+			int zero(void);
+			zero();`,
+			expected: 0,
+		},
+		{
+			input: `void print(...);
+			void printf(char *format, ...);
+			printf("");
+			7;`,
+			expected: 7,
+		},
+		{
+			input: `int main(){ int helper(int); return helper(20); }
+			int helper(int n){ return n + 1; }
+			main();`,
+			expected: 21,
+		},
+		{
+			input: `int twice(int n){ return n * 2; }
+			int main(){ { int twice(int); return twice(4); } }
+			main();`,
+			expected: 8,
+		},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"int helper(int);\nhelper(1);",
+			"2:7: link error: undefined reference to function helper, it is declared but never defined",
+		},
+		{
+			"int main(){ int helper(int); return helper(1); }\nmain();",
+			"link error: undefined reference to function helper, it is declared but never defined",
+		},
+		{
+			"int main(){ float helper(int); return helper(1); }\nint helper(int n){ return n; }\nmain();",
+			"conflicting types for function helper, return type INTEGER_OBJ does not match the earlier declaration's FLOAT_OBJ",
+		},
+		{
+			"int f(int, float);\nint f(int a, int b){ return a; }",
+			"2:1: conflicting types for function f, parameter 2 is int but float in the earlier declaration",
+		},
+		{
+			"int f(int);\nint f(int a, int b){ return a; }",
+			"conflicting types for function f, 2 parameters do not match the earlier declaration's 1",
+		},
+		{
+			"float f(int);\nint f(int a){ return a; }",
+			"conflicting types for function f, return type INTEGER_OBJ does not match the earlier declaration's FLOAT_OBJ",
+		},
+		{
+			"int f(int a){ return a; }\nint f(int a){ return a; }",
+			"2:1: function redefinition error: function f already defined before",
+		},
+		{
+			"int f(int a){ return a; }\nint f(int, ...);",
+			"conflicting types for function f, 1+ parameters do not match the earlier declaration's 1",
+		},
+		{
+			"int x = 1;\nint x(int);",
+			"variable redeclaration error: variable x already declared before",
+		},
+	}
+
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestArrayParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{
+			input: `int sum(int arr[], int n){ int s = 0; for (int i = 0; i < n; i++) { s += arr[i]; } return s; }
+			int a[4] = {1, 2, 3, 4};
+			sum(a, 4);`,
+			expected: 10,
+		},
+		{
+			input: `void sort(int arr[5], int n){
+				for (int i = 0; i < n - 1; i++) {
+					for (int j = 0; j < n - 1 - i; j++) {
+						if (arr[j] > arr[j + 1]) { int t = arr[j]; arr[j] = arr[j + 1]; arr[j + 1] = t; }
+					}
+				}
+			}
+			int a[5] = {5, 3, 9, 1, 4};
+			sort(a, 5);
+			a[0] * 10000 + a[1] * 1000 + a[2] * 100 + a[3] * 10 + a[4];`,
+			expected: 13459,
+		},
+		{
+			input: `int search(int [], int, int);
+			int search(int *arr, int n, int key){ for (int i = 0; i < n; i++) { if (arr[i] == key) { return i; } } return -1; }
+			int a[3] = {7, 8, 9};
+			search(a, 3, 9) * 10 + search(a, 3, 5);`,
+			expected: 19,
+		},
+		{
+			input: `void clear(int arr[], int n){ for (int i = 0; i < n; i++) { arr[i] = 0; } }
+			int a[4] = {1, 2, 3, 4};
+			clear(&a[2], 2);
+			a[0] + a[1] + a[2] + a[3];`,
+			expected: 3,
+		},
+		{
+			input: `struct Point { int x; int y; };
+			void shift(struct Point pts[], int n){ for (int i = 0; i < n; i++) { pts[i].x = pts[i].x + 1; } }
+			struct Point ps[2];
+			shift(ps, 2);
+			shift(ps, 1);
+			ps[0].x * 10 + ps[1].x;`,
+			expected: 21,
+		},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"int f(int arr[]){ return arr[0]; }\nfloat x[2];\nf(x);",
+			"error calling function f, type of parameter arr mismatch, expected int *, got float [2]",
+		},
+		{
+			"int f(int arr[]){ return arr[0]; }\nf(3);",
+			"error calling function f, type of parameter arr mismatch, expected int *, got INTEGER_OBJ",
+		},
+		{
+			"int f(int arr[]);\nint f(float *arr){ return 0; }",
+			"conflicting types for function f, parameter 1 is float * but int * in the earlier declaration",
+		},
+	}
+
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestMultiDimensionalArrays(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int m[2][3] = {{1, 2, 3}, {4, 5, 6}}; m[1][2] * 10 + m[0][1];", 62},
+		{"int m[2][3] = {{1}, {4, 5}}; m[0][0] + m[0][2] + m[1][1] * 10 + m[1][2];", 51},
+		{"int m[][2] = {{1, 2}, {3, 4}, {5, 6}}; m[2][1];", 6},
+		{"int m[2][2]; m[1][0] = 7; m[0][1] += 2; m[1][0]++; m[1][0] * 10 + m[0][1];", 82},
+		{"int c[2][3][4]; c[1][2][3] = 9; c[1][2][3] + c[0][0][0];", 9},
+		{
+			input: `int m[3][4];
+			for (int i = 0; i < 3; i++) { for (int j = 0; j < 4; j++) { m[i][j] = i * 4 + j; } }
+			int *p = &m[0][0];
+			int ok = 1;
+			for (int k = 0; k < 12; k++) { if (*(p + k) != k) { ok = 0; } }
+			ok * 100 + (&m[2][0] - &m[0][0]);`,
+			expected: 108,
+		},
+		{
+			input: `int sum(int row[], int n){ int s = 0; for (int i = 0; i < n; i++) { s += row[i]; } return s; }
+			int m[2][3] = {{1, 2, 3}, {4, 5, 6}};
+			sum(m[1], 3) * 10 + sum(m[0], 3);`,
+			expected: 156,
+		},
+		{"int m[2][3] = {{1, 2, 3}}; m[0][2] * 10 + m[1][2];", 30},
+		{"int a[4] = {7}; a[0] + a[3];", 7},
+		{"int m[2][3] = {{1, 2, 3}, {4, 5, 6}}; *(*(m + 1) + 2);", 6},
+		{"int m[2][3] = {{1, 2, 3}, {4, 5, 6}}; **m * 10 + (m + 1)[0][1];", 15},
+		{"int m[2][3] = {{1, 2, 3}, {4, 5, 6}}; int *r = *(m + 1); r[0] * 10 + ((m + 2) - m);", 42},
+		{"int c[2][3][4]; c[1][2][3] = 9; *(*(*(c + 1) + 2) + 3);", 9},
+		{
+			input: `void fill(int *p, int n){ for (int i = 0; i < n; i++) { p[i] = i; } }
+			int m[2][3];
+			fill(m[0], 6);
+			m[1][2];`,
+			expected: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int m[2][3];\nm[1] = 4;", "2:1: type error: array m[1] is not assignable"},
+		{"int m[2][3];\nm[0]++;", "type error: array m[0] is not assignable"},
+		{"int m[2][3] = {{1, 2, 3, 4}, {5}};", "type error: too many initializers for array of length 3"},
+		{"int m[2][3] = {{1, 2, 'c'}, {4, 5, 6}};", "type error: invalid declaration type cannot assign CHAR_OBJ to INTEGER_OBJ"},
+		{"int m[2][3];\nm[1][i];", "2:6: variable error: variable i not declared in this scope"},
+		{"int m[2][3];\nm[1][1.5];", "invalid index type, expected an integer, got FLOAT_OBJ"},
+		{"int m[2][3];\nint *p = m;", "2:1: type error: invalid declaration type cannot assign int [2][3] to int *"},
+		{"int m[2][3];\n*m = 0;", "2:1: type error: array (*m) is not assignable"},
+		{"int m[2][3];\n(*(m + 1))++;", "type error: array (*(m + 1)) is not assignable"},
+		{"int m[2][3];\nint f(int *p) { return *p; }\nf(m);", "type of parameter p mismatch"},
+	}
+
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestIndexBounds(t *testing.T) {
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int a[3];\na[-1];", "2:2: invalid index, index -1 out of bounds for array a of length 3"},
+		{"int a[3];\na[3];", "2:2: invalid index, index 3 out of bounds for array a of length 3"},
+		{"int a[3];\na[-1] = 5;", "2:1: invalid index, index -1 out of bounds for array a of length 3"},
+		{"int a[3];\nint i = 7;\na[i] += 1;", "invalid index, index 7 out of bounds for array a of length 3"},
+		{"int a[3];\na[-2]++;", "invalid index, index -2 out of bounds for array a of length 3"},
+		{"int a[3];\n--a[3];", "invalid index, index 3 out of bounds for array a of length 3"},
+		{"int a[3];\nint *p = &a[-1];", "invalid index, index -1 out of bounds for array a of length 3"},
+		{"int a[3];\nint *p = &a[4];", "invalid index, index 4 out of bounds for array a of length 3"},
+		{"int m[2][3];\nm[2][0];", "invalid index, index 2 out of bounds for array m of length 2"},
+		{"int m[2][3];\nm[1][-1] = 4;", "invalid index, index -1 out of bounds for array m[1] of length 3"},
+		{"string s = \"abc\";\ns[-1];", "2:2: invalid index, index -1 out of bounds for string s of length 3"},
+		{"string s = \"abc\";\ns[3] = 'x';", "invalid index, index 3 out of bounds for string s of length 3"},
+		{"int f(int a[]){ return a[-1]; }\nint a[2];\nf(a);", "segmentation fault: invalid memory access"},
+		{"int a[2];\na[0] = 1.5;", "2:1: type error,cannot assign FLOAT_OBJ to INTEGER_OBJ"},
+		{"int a[3];\nprintf(\"%d\\n\", a[-1]);", "2:17: invalid index, index -1 out of bounds for array a of length 3"},
+		{"string s = \"ab\";\nprint(s[0], s[2]);", "2:14: invalid index, index 2 out of bounds for string s of length 2"},
+	}
+
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+
+	// the address one past the last element may be taken but not used
+	testIntegerObject(t, testEval(t, "int a[3];\nint *end = &a[3];\nend - &a[0];"), 3)
+
+	env := obj.NewEnv()
+	Eval(parser.New("int arr[2]; string s = \"ab\";").ParseProgram(), env)
+	for _, name := range []string{"arr", "s"} {
+		val, _ := env.GetVar(name)
+		if _, err := env.Index(val, -1); err == nil {
+			t.Errorf("Expected an error indexing %s with -1", name)
+		} else if !strings.Contains(err.Error(), "invalid index, index -1 out of bounds for") {
+			t.Errorf("Expected a bounds error indexing %s with -1, got %q", name, err)
+		}
+		if err := env.SetIndex(val, -1, &obj.CharObject{Value: 'x'}); err == nil {
+			t.Errorf("Expected an error assigning %s[-1]", name)
+		}
+	}
+}
+
+func TestIndexedExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"int g[3] = {7, 8, 9}; int *getArr() { return g; } getArr()[1];", 8},
+		{"int g[3] = {7, 8, 9}; int *getArr() { return g; } getArr()[2] = 4; g[2];", 4},
+		{`"abc"[1];`, 'b'},
+		{`string word() { return "hey"; } word()[2];`, 'y'},
+		{`string s = "xyz"; int i = 0; (s)[i];`, 'x'},
+		{"int a[4] = {1, 2, 3, 4}; (a + 1)[2];", 4},
+		{"struct S { int arr[3]; }; struct S s; s.arr[1] = 5; s.arr[2]++; s.arr[1] * 10 + s.arr[2];", 51},
+		{"struct S { int m[2][2]; }; struct S s; s.m[1][0] = 6; s.m[1][0];", 6},
+		{"struct S { int arr[2]; }; struct S s; struct S *p = &s; p->arr[1] = 3; s.arr[1];", 3},
+		{"struct S { int arr[2]; }; struct S rows[2]; rows[1].arr[0] = 2; rows[1].arr[0] + rows[0].arr[0];", 2},
+		{"struct S { int arr[3]; }; struct S s; int *q = &s.arr[1]; *q = 11; s.arr[1];", 11},
+	}
+
+	for _, tt := range tests {
+		result := testEval(t, tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, result, expected)
+		case rune:
+			testCharObject(t, result, byte(expected))
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int x = 3;\nx[0];", "2:2: type error: subscripted value x is not an array, string or pointer, got INTEGER_OBJ"},
+		{"int f() { return 1; }\nf()[0];", "type error: subscripted value f() is not an array, string or pointer, got INTEGER_OBJ"},
+		{"struct S { int a[2]; };\nstruct S s;\ns.a[2] = 1;", "3:1: invalid index, index 2 out of bounds for array s.a of length 2"},
+		{`"abc"[3];`, `invalid index, index 3 out of bounds for string "abc" of length 3`},
+		{"undeclared()[0];", "1:11: error calling function undeclared, function not found"},
+	}
+
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
 func TestWhileStatement(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -645,6 +1168,8 @@ func TestWhileStatement(t *testing.T) {
 		{"while(false){123;}", []int{}},
 		{"int x = 5; while(x < 0){x;}", []int{}},
 		{"int a = 2; int b = 3; while(a * b < 10){a * b; a = a + 1;}", []int{6, 9}},
+		{"int n = 3; while (n) --n;", []int{2, 1, 0}},
+		{"int x = 0; int *p = &x; while (x < 3) (*p)++;", []int{0, 1, 2}},
 	}
 
 	for i, tt := range tests {
@@ -710,6 +1235,19 @@ func TestWhileStatement(t *testing.T) {
 			testIntegerObject(t, object, tt.expected[j])
 		}
 	}
+
+	// an error in the condition ends the loop instead of counting as true
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int a[3] = {1, 2, 3};\nint i = 0;\nwhile (a[i] > 0) { i++; }", "3:9: invalid index, index 3 out of bounds for array a of length 3"},
+		{"while (undefined) { }", "1:8: variable error: variable undefined not declared in this scope"},
+		{"int n = 0;\nwhile (n < 2 && n / (1 - n) >= 0) { n++; }", "2:19: runtime error: devide by zero"},
+	}
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
 }
 
 func TestForStatement(t *testing.T) {
@@ -843,7 +1381,7 @@ func TestArrayDeclarationStatement(t *testing.T) {
 			varName:    "empty",
 			dataType:   obj.INTEGER_OBJ,
 			length:     10,
-			valueCount: 0,
+			valueCount: 10,
 		},
 	}
 
@@ -963,9 +1501,10 @@ func TestArrayAssignmentStatement(t *testing.T) {
 			t.Fatalf("[%d] - Assignment error: %s", i, result.String())
 		}
 
-		modifiedVal, err := env.GetIndexVar(tt.arrayName, tt.index)
+		arr, _ := env.GetVar(tt.arrayName)
+		modifiedVal, err := env.Index(arr, tt.index)
 		if err != nil {
-			t.Fatalf("[%d] - GetIndexVar error: %s", i, err.Error())
+			t.Fatalf("[%d] - Index error: %s", i, err.Error())
 		}
 
 		switch expected := tt.expectedVal.(type) {
@@ -1048,3 +1587,375 @@ func TestArrayAssignmentStatement(t *testing.T) {
 		t.Fatalf("Expected type mismatch error, got %T", result)
 	}
 }
+
+func TestPointers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"int x = 5; int *p = &x; *p;", 5},
+		{"int x = 5; int *p = &x; *p = 10; x;", 10},
+		{"int x = 5; int *p = &x; *p += 3; x;", 8},
+		{"float f = 1.5; float *p = &f; *p * 2;", 3.0},
+		{"char c = 'a'; char *p = &c; *p = 'z'; c;", byte('z')},
+		{"int x = 1; int *p = &x; int **pp = &p; **pp = 7; x;", 7},
+		{"int x = 1; int y = 2; int *p = &x; p = &y; *p;", 2},
+		{"int arr[3] = {10, 20, 30}; int *p = arr; *(p + 2);", 30},
+		{"int arr[3] = {10, 20, 30}; int *p = &arr[1]; *(p - 1);", 10},
+		{"int arr[3] = {10, 20, 30}; int *p = arr; p[1];", 20},
+		{"int arr[3] = {10, 20, 30}; int *p = arr; p[2] = 99; arr[2];", 99},
+		{"int arr[3] = {10, 20, 30}; *(arr + 1);", 20},
+		{"int arr[4] = {1, 2, 3, 4}; &arr[3] - &arr[0];", 3},
+		{"int arr[2] = {1, 2}; int *p = arr; int *q = arr + 1; p < q;", true},
+		{"int x = 1; int *p = &x; int *q = &x; p == q;", true},
+		{"int *p = 0; p == 0;", true},
+		{"int *p; !p;", true},
+		{"int x = 3; int *p = &x; if(p){1;}else{2;}", 1},
+		{"int x = 3; void *v = &x; int *p = v; *p;", 3},
+		{"int sq(int *n){ *n = *n * *n; return *n; } int v = 4; sq(&v); v;", 16},
+	}
+
+	for i, tt := range tests {
+		env := obj.NewEnv()
+		p := parser.New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+
+		var result obj.Object
+		for _, stmt := range program.Statements {
+			result = Eval(stmt, env)
+			if result.Type() == obj.ERROR_OBJ {
+				t.Fatalf("[%d] - Evaluation error: %s", i, result.String())
+			}
+		}
+		if results, ok := result.(*obj.ResultsObject); ok {
+			result = results.Results[len(results.Results)-1]
+		}
+
+		switch val := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, result, val)
+		case float64:
+			testFloatObject(t, result, val)
+		case bool:
+			testBooleanObject(t, result, val)
+		case byte:
+			testCharObject(t, result, val)
+		default:
+			t.Fatalf("Test [%d]: Unsupported expected type %T", i, val)
+		}
+	}
+
+	errorTests := []string{
+		"int *p = 0; *p;",
+		"int *p; *p = 1;",
+		"int x = 1; *x;",
+		"int x = 1; float *p = &x;",
+		"int x = 1; int *p = &x; *(p + 1);",
+		"int x = 1; int *p = &x; *p = 2.5;",
+		"void *v = 0; *v;",
+		"&5;",
+	}
+
+	for i, input := range errorTests {
+		env := obj.NewEnv()
+		p := parser.New(input)
+		program := p.ParseProgram()
+
+		var result obj.Object
+		for _, stmt := range program.Statements {
+			result = Eval(stmt, env)
+			if result.Type() == obj.ERROR_OBJ {
+				break
+			}
+		}
+		if result.Type() != obj.ERROR_OBJ {
+			t.Errorf("[%d] - Expected error for %q, got %s", i, input, result.Type())
+		}
+	}
+
+	typeTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int x = 1; float *p = &x;", "cannot assign int * to float *"},
+		{"char c = 'a'; char *p = &c; char **pp = &p; int *q; q = pp;", "cannot assign char ** to int *"},
+		{"struct S { int x; }; struct S s; int *p = &s;", "cannot assign struct S * to int *"},
+		{"int *a[2]; int *p = a;", "cannot assign int *[2] to int *"},
+		{"int m[2][3]; int **pp = m + 1;", "cannot assign int (*)[3] to int **"},
+		{"void *v = 0; int x = v;", "cannot assign void * to INTEGER_OBJ"},
+	}
+	for _, tt := range typeTests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestStructs(t *testing.T) {
+	structs := "struct Point { int x; int y; }; struct Line { struct Point a; struct Point b; int w[2]; }; union Data { int i; char c; }; union Word { int i; char c[4]; };"
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"struct Point p; p.x;", 0},
+		{"struct Point p = {3, 4}; p.y;", 4},
+		{"struct Point p = {3}; p.y;", 0},
+		{"struct Point p; p.x = 7; p.x += 1; p.x;", 8},
+		{"struct Point p = {1, 2}; struct Point q = p; q.x = 9; p.x;", 1},
+		{"struct Point p = {1, 2}; struct Point q; q = p; p.y = 5; q.y;", 2},
+		{"struct Line l = {{1, 2}, {3, 4}, {5, 6}}; int *w = l.w; l.b.x + w[1];", 9},
+		{"struct Line l; l.a.y = 6; struct Line m = l; m.a.y;", 6},
+		{"struct Point p; struct Point *pp = &p; pp->x = 11; p.x;", 11},
+		{"struct Point p = {1, 2}; struct Point *pp = &p; (*pp).y;", 2},
+		{"struct Point p = {1, 2}; int *py = &p.y; *py = 20; p.y;", 20},
+		{"struct Line l; struct Point *pb = &l.b; pb->y = 3; l.b.y;", 3},
+		{"struct Point p; struct Point q = {4, 5}; struct Point *pp = &p; *pp = q; p.y;", 5},
+		{"struct Point pts[2] = {{1, 2}, {3, 4}}; pts[1].x;", 3},
+		{"struct Point pts[2]; struct Point *pp = pts; pp = pp + 1; pp->y = 8; pts[1].y;", 8},
+		{"struct Point pts[2]; struct Point q = {6, 7}; pts[0] = q; q.x = 0; pts[0].x;", 6},
+		{"struct Point pts[2]; pts[0].y = 4; pts[0].y;", 4},
+		{"void set(struct Point p){ p.x = 100; } struct Point p = {1, 2}; set(p); p.x;", 1},
+		{"void set(struct Point *p){ p->x = 100; } struct Point p = {1, 2}; set(&p); p.x;", 100},
+		{"struct Point make(int x){ struct Point p = {x, x}; return p; } struct Point q = make(6); q.y;", 6},
+		{"union Data d; d.i = 66; d.c;", byte('B')},
+		{"union Data d = {65}; d.c = 'C'; d.i;", 67},
+		{"union Data u; u.i = 65; u.c++; u.i;", 66},
+		{"union Data u; u.i = 66; u.c--; u.c;", byte('A')},
+		{"union Data u; u.i = 65; char *pc = &u.c; *pc;", byte('A')},
+		{"union Data u; u.i = 65; char *pc = &u.c; *pc = 'B'; u.i;", 66},
+		{"union Data u; int *pi = &u.i; *pi = 68; u.c;", byte('D')},
+		{"union Data u = {65}; union Data *pu = &u; pu->c = 'E'; pu->i;", 69},
+		{"struct Box { int n; union Data d; }; struct Box b; char *pc = &b.d.c; b.d.i = 70; *pc;", byte('F')},
+		{"union Word w; w.i = 65; w.c[0];", byte('A')},
+		{"union Word w; w.i = 0x41424344; w.c[3];", byte('A')},
+		{"union Word w; w.c[0] = 'A'; w.i;", 65},
+		{"union Word w; w.c[1] = 'B'; w.c[0] = 'A'; w.i;", 0x4241},
+		{"union Word w; w.i = 65; w.c[1]++; w.i;", 321},
+		{"union Word w; char *pc = w.c; pc[2] = 'A'; w.i;", 0x410000},
+		{"union Word w; char *pc = w.c; w.i = 66; pc[0];", byte('B')},
+		{"union Word w = {7}; union Word v = w; v.c[0] = 'A'; w.i * 100 + v.i;", 765},
+		{"struct Pair { char a; char b; }; union Both { int i; struct Pair p; }; union Both u; u.i = 0x4241; u.p.b;", byte('B')},
+		{"struct Pair { char a; char b; }; union Both { int i; struct Pair p; }; union Both u; struct Pair *pp = &u.p; pp->b = 'A'; u.i;", 0x4100},
+		{"struct Node { int v; struct Node *next; }; struct Node a = {1}; struct Node b = {2}; a.next = &b; a.next->v;", 2},
+	}
+
+	for i, tt := range tests {
+		env := obj.NewEnv()
+		p := parser.New(structs + tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+
+		var result obj.Object
+		for _, stmt := range program.Statements {
+			result = Eval(stmt, env)
+			if result.Type() == obj.ERROR_OBJ {
+				t.Fatalf("[%d] - Evaluation error: %s", i, result.String())
+			}
+		}
+
+		switch val := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, result, val)
+		case byte:
+			testCharObject(t, result, val)
+		default:
+			t.Fatalf("Test [%d]: Unsupported expected type %T", i, val)
+		}
+	}
+
+	errorTests := []string{
+		"struct Missing m;",
+		"struct Point p; p.z;",
+		"struct Point p; p.x = 'a';",
+		"int x; x.y;",
+		"struct Point p; p->x;",
+		"struct Point *pp = 0; pp->x;",
+		"struct Point p; struct Line l; p = l;",
+		"struct Point p = {1, 2, 3};",
+		"union Data d = {1, 'a'};",
+		"struct Line l; l.w = l.w;",
+		"struct Point { int z; };",
+		"struct Dup { int x; int x; };",
+		"struct Self { struct Self s; };",
+		"union Point p;",
+		"struct Point p; p = {1, 2};",
+	}
+
+	for i, input := range errorTests {
+		env := obj.NewEnv()
+		p := parser.New(structs + input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+
+		var result obj.Object
+		for _, stmt := range program.Statements {
+			result = Eval(stmt, env)
+			if result.Type() == obj.ERROR_OBJ {
+				break
+			}
+		}
+		if result.Type() != obj.ERROR_OBJ {
+			t.Errorf("[%d] - Expected error for %q, got %s", i, input, result.Type())
+		}
+	}
+}
+
+func TestSwitchStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int x = 2; int y = 0; switch (x) { case 1: y = 1; break; case 2: y = 2; break; case 3: y = 3; } y;", 2},
+		{"int x = 1; int y = 0; switch (x) { case 1: y += 1; case 2: y += 2; case 3: y += 3; } y;", 6},
+		{"int x = 9; int y = 0; switch (x) { default: y = 7; break; case 1: y = 1; } y;", 7},
+		{"int x = 9; int y = 0; switch (x) { case 1: y = 1; default: y += 7; case 2: y += 2; } y;", 9},
+		{"int x = 9; int y = 5; switch (x) { case 1: y = 1; } y;", 5},
+		{"char c = 'b'; int y = 0; switch (c) { case 'a': y = 1; break; case 'b': y = 2; break; } y;", 2},
+		{"int x = 98; int y = 0; switch (x) { case 'b': y = 2; } y;", 2},
+		{"int x = -1; int y = 0; switch (x) { case -1: y = 4; } y;", 4},
+		{"int x = 1; int y = 0; switch (x) { case 1: if (x == 1) { y = 3; break; } y = 4; } y;", 3},
+		{"int x = 1; int y = 0; switch (x) { case 1: switch (y) { case 0: y = 5; break; } y += 1; } y;", 6},
+		{"int f(int x){ switch (x) { case 1: return 10; default: return 20; } } f(1) + f(2);", 30},
+		{"int x = 2; int y = 0; switch (x + 1) { case 3: int z = 4; y = z; } y;", 4},
+	}
+
+	for i, tt := range tests {
+		env := obj.NewEnv()
+		p := parser.New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+
+		var result obj.Object
+		for _, stmt := range program.Statements {
+			result = Eval(stmt, env)
+			if result.Type() == obj.ERROR_OBJ {
+				t.Fatalf("[%d] - Evaluation error: %s", i, result.String())
+			}
+		}
+		testIntegerObject(t, result, tt.expected)
+	}
+
+	errorTests := []string{
+		"float f = 1.5; switch (f) { case 1: f = 2.0; }",
+		"string s = \"a\"; switch (s) { default: s = \"b\"; }",
+		"int x = 1; switch (x) { case 1: y = 2; }",
+	}
+
+	for i, input := range errorTests {
+		env := obj.NewEnv()
+		p := parser.New(input)
+		program := p.ParseProgram()
+
+		var result obj.Object
+		for _, stmt := range program.Statements {
+			result = Eval(stmt, env)
+			if result.Type() == obj.ERROR_OBJ {
+				break
+			}
+		}
+		if result.Type() != obj.ERROR_OBJ {
+			t.Errorf("[%d] - Expected error for %q, got %s", i, input, result.Type())
+		}
+	}
+}
+
+func TestBreakContinue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int i = 0; while (i < 10) { if (i == 4) { break; } i += 1; } i;", 4},
+		{"int i = 0; int sum = 0; while (i < 5) { i += 1; if (i == 2) { continue; } sum += i; } sum;", 13},
+		{"int sum = 0; for (int i = 0; i < 10; i += 1) { if (i == 5) { break; } sum += i; } sum;", 10},
+		{"int sum = 0; for (int i = 0; i < 6; i += 1) { if (i % 2 == 1) { continue; } sum += i; } sum;", 6},
+		{"int n = 0; for (int i = 0; i < 3; i += 1) { for (int j = 0; j < 3; j += 1) { if (j == 2) { break; } n += 1; } } n;", 6},
+		{"int n = 0; int i = 0; while (i < 5) { i += 1; switch (i) { case 3: continue; default: break; } n += 1; } n;", 4},
+		{"int n = 0; while (1) { n += 1; if (n < 3) { continue; } else { break; } } n;", 3},
+		{"int f(){ int i = 0; while (1) { i += 1; if (i == 7) { return i; } } return 0; } f();", 7},
+	}
+
+	for i, tt := range tests {
+		env := obj.NewEnv()
+		p := parser.New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+
+		var result obj.Object
+		for _, stmt := range program.Statements {
+			result = Eval(stmt, env)
+			if result.Type() == obj.ERROR_OBJ {
+				t.Fatalf("[%d] - Evaluation error: %s", i, result.String())
+			}
+		}
+		testIntegerObject(t, result, tt.expected)
+	}
+}
+
+func TestDoWhileStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"int i = 0; do { i += 1; } while (i < 5); i;", 5},
+		{"int i = 10; do { i += 1; } while (i < 5); i;", 11},
+		{"int i = 0; do { i += 1; if (i == 3) { break; } } while (i < 10); i;", 3},
+		{"int i = 0; int sum = 0; do { i += 1; if (i == 2) { continue; } sum += i; } while (i < 4); sum;", 8},
+		{"int f(){ int i = 0; do { i += 2; if (i > 5) { return i; } } while (1); return 0; } f();", 6},
+	}
+
+	for i, tt := range tests {
+		env := obj.NewEnv()
+		p := parser.New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+
+		var result obj.Object
+		for _, stmt := range program.Statements {
+			result = Eval(stmt, env)
+			if result.Type() == obj.ERROR_OBJ {
+				t.Fatalf("[%d] - Evaluation error: %s", i, result.String())
+			}
+		}
+		testIntegerObject(t, result, tt.expected)
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"int a = 1;\nint b = a / 0;", "prog.c:2:11: "},
+		{"int a = 1;\n\nc = 2;", "prog.c:3:1: "},
+		{"int f(int x) {\n\treturn x + missing;\n}\nf(1);", "prog.c:2:13: "},
+		{"int a[2] = {1, 2};\nint b = a[5];", "prog.c:2:"},
+	}
+
+	for i, tt := range tests {
+		env := obj.NewEnv()
+		p := parser.NewFile("prog.c", tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		result := Eval(program, env)
+		errorObj, ok := result.(*obj.ErrorObject)
+		if !ok {
+			t.Fatalf("[%d] - Expected error for %q, got %s", i, tt.input, result.Type())
+		}
+		if !strings.HasPrefix(errorObj.String(), tt.expected) {
+			t.Errorf("[%d] - Expected error at %s, got %s", i, tt.expected, errorObj.String())
+		}
+	}
+}
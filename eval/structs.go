@@ -0,0 +1,214 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mohamedirfanam/cynterpreter/eval/obj"
+	"github.com/mohamedirfanam/cynterpreter/lexer/token"
+	"github.com/mohamedirfanam/cynterpreter/parser/ast"
+)
+
+func evalStructDeclaration(sd *ast.StructDeclaration, env *obj.Environment) obj.Object {
+	if env.StructDeclaredInScope(sd.Name.Value) {
+		return obj.NewError(fmt.Errorf("type redeclaration error: %s %s already declared before", sd.TokenLexeme(), sd.Name))
+	}
+	def := &obj.StructType{Name: sd.Name.Value, Union: sd.IsUnion()}
+	// members are resolved in a scope that already knows the type, so that
+	// they can point to it, the type is only published once it is complete
+	scope := env.NewScope()
+	scope.SetStruct(def.Name, def)
+	for _, member := range sd.Members {
+		for _, d := range member.Declarators {
+			name := d.Identifier.Value
+			if def.FieldIndex(name) != -1 {
+				return obj.NewError(fmt.Errorf("member redeclaration error: member %s already declared in %s", name, def))
+			}
+			if isStructType(member.Type) && member.TypeName == def.Name && d.Pointers == 0 {
+				return obj.NewError(fmt.Errorf("type error: member %s has incomplete type %s", name, def))
+			}
+			zero := getDefaultVal(member.Type, member.TypeName, d.Pointers, scope)
+			if zero.Type() == obj.ERROR_OBJ {
+				return zero
+			}
+			if arr, ok := d.Literal.(*ast.ArrayDeclaration); ok {
+				zero = obj.GetArrayObject(zero.Type(), arr.Length, nil, zeroRow(zero, arr.Dims))
+			}
+			def.Fields = append(def.Fields, name)
+			def.Zero = append(def.Zero, zero)
+		}
+	}
+	env.SetStruct(def.Name, def)
+	return obj.NULL
+}
+
+func evalMemberExpression(me *ast.MemberExpression, env *obj.Environment) obj.Object {
+	st, index, errObj := evalMemberOperand(me, env)
+	if errObj != nil {
+		return errObj
+	}
+	return st.Vals[index]
+}
+
+// evalMemberOperand finds the struct a member expression refers to and the
+// index of the member inside it.
+func evalMemberOperand(me *ast.MemberExpression, env *obj.Environment) (*obj.StructObject, int, obj.Object) {
+	val := Eval(me.Exp, env)
+	if val.Type() == obj.ERROR_OBJ {
+		return nil, 0, val
+	}
+	if me.IsArrow() {
+		ptr, ok := toPointer(val)
+		if !ok || ptr.IsRow() || ptr.Depth != 1 || ptr.Struct == nil {
+			return nil, 0, obj.NewError(fmt.Errorf("type error: Invalid operand type for -> operator, expected pointer to struct but got %s", val.Type()))
+		}
+		st, err := env.Memory().LoadStruct(ptr.Addr, ptr.Struct)
+		if err != nil {
+			return nil, 0, obj.NewError(err)
+		}
+		val = st
+	}
+	st, ok := val.(*obj.StructObject)
+	if !ok {
+		return nil, 0, obj.NewError(fmt.Errorf("type error: Invalid operand type for %s operator, expected struct but got %s", me.Token.Lexeme, val.Type()))
+	}
+	index := st.Def.FieldIndex(me.Member.Value)
+	if index == -1 {
+		return nil, 0, obj.NewError(fmt.Errorf("member error: %s has no member named %s", st.Def, me.Member))
+	}
+	return st, index, nil
+}
+
+func evalMemberAssignment(me *ast.MemberExpression, val obj.Object, env *obj.Environment) obj.Object {
+	st, index, errObj := evalMemberOperand(me, env)
+	if errObj != nil {
+		return errObj
+	}
+	field := st.Vals[index]
+	if field.Type() == obj.ARRAY_OBJ {
+		return obj.NewError(fmt.Errorf("type error: array %s is not assignable", me))
+	}
+	converted, ok := convertForAssignment(field, val)
+	if !ok {
+		return obj.NewError(fmt.Errorf("type error: invalid assigment type cannot assign %s to %s", typeString(val), typeString(field)))
+	}
+	env.SetField(st, index, converted)
+	return converted
+}
+
+// evalInitializer evaluates the initializer of a variable starting out as
+// target. Brace enclosed lists fill aggregates element by element, a union
+// only takes a value for its first member.
+func evalInitializer(target obj.Object, exp ast.Expression, env *obj.Environment) obj.Object {
+	list, ok := exp.(*ast.InitializerList)
+	if !ok {
+		val := Eval(exp, env)
+		if val.Type() == obj.ERROR_OBJ {
+			return val
+		}
+		converted, ok := convertForAssignment(target, val)
+		if !ok {
+			return obj.NewError(fmt.Errorf("type error: invalid declaration type cannot assign %s to %s", typeString(val), typeString(target)))
+		}
+		return converted
+	}
+	switch target := target.(type) {
+	case *obj.StructObject:
+		limit := len(target.Vals)
+		if target.Def.Union {
+			limit = 1
+		}
+		if len(list.Values) > limit {
+			return obj.NewError(fmt.Errorf("type error: too many initializers for %s", target.Def))
+		}
+		for i, exp := range list.Values {
+			val := evalInitializer(target.Vals[i], exp, env)
+			if val.Type() == obj.ERROR_OBJ {
+				return val
+			}
+			target.SetField(i, val)
+		}
+	case *obj.ArrayObject:
+		if len(list.Values) > target.Length {
+			return obj.NewError(fmt.Errorf("type error: too many initializers for array of length %d", target.Length))
+		}
+		for i, exp := range list.Values {
+			val := evalInitializer(target.Vals[i], exp, env)
+			if val.Type() == obj.ERROR_OBJ {
+				return val
+			}
+			target.Vals[i] = val
+		}
+	default:
+		return obj.NewError(fmt.Errorf("type error: initializer list cannot initialize %s", target.Type()))
+	}
+	return target
+}
+
+func isStructType(tknType token.TokenType) bool {
+	return tknType == token.STRUCT || tknType == token.UNION
+}
+
+// typeString names the type of val in diagnostics, struct values are named
+// by their tag since they all share STRUCT_OBJ, and pointers and arrays are
+// spelled like their C declarations, such as char ** or int [2][3].
+func typeString(val obj.Object) string {
+	switch val := val.(type) {
+	case *obj.StructObject:
+		return val.Def.String()
+	case *obj.PointerObject:
+		name := elemTypeString(val.DataType, val.Struct) + " "
+		if !val.IsRow() {
+			return name + strings.Repeat("*", val.Depth)
+		}
+		// a pointer to a row of int m[2][3] is an int (*)[3]
+		name += strings.Repeat("*", val.Depth-1) + "(*)"
+		for _, dim := range val.Dims {
+			name += fmt.Sprintf("[%d]", dim)
+		}
+		return name
+	case *obj.ArrayObject:
+		// int m[2][3] is an int [2][3] and char *names[2] a char *[2]
+		dims := ""
+		var elem obj.Object = val
+		for arr, ok := val, true; ok; arr, ok = elem.(*obj.ArrayObject) {
+			dims += fmt.Sprintf("[%d]", arr.Length)
+			if len(arr.Vals) == 0 {
+				return elemTypeString(arr.DataType, nil) + " " + dims
+			}
+			elem = arr.Vals[0]
+		}
+		switch elem.(type) {
+		case *obj.PointerObject:
+			return typeString(elem) + dims
+		case *obj.StructObject:
+			return typeString(elem) + " " + dims
+		}
+		return elemTypeString(elem.Type(), nil) + " " + dims
+	}
+	return string(val.Type())
+}
+
+// elemTypeString names the type a pointer points to or an array holds, def
+// is the definition of struct types.
+func elemTypeString(t obj.ObjType, def *obj.StructType) string {
+	switch t {
+	case obj.INTEGER_OBJ:
+		return "int"
+	case obj.CHAR_OBJ:
+		return "char"
+	case obj.BOOLEAN_OBJ:
+		return "bool"
+	case obj.FLOAT_OBJ:
+		return "float"
+	case obj.STRING_OBJ:
+		return "string"
+	case obj.NULL_OBJ:
+		return "void"
+	case obj.STRUCT_OBJ:
+		if def != nil {
+			return def.String()
+		}
+	}
+	return string(t)
+}
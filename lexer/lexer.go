@@ -1,6 +1,10 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/mohamedirfanam/cynterpreter/lexer/token"
 )
 
@@ -9,6 +13,12 @@ type Lexer struct {
 	ch       byte
 	position int
 	pointer  int
+	errors   []error
+
+	// location of ch in the source
+	file   string
+	line   int
+	column int
 }
 
 func New(input string) *Lexer {
@@ -16,28 +26,69 @@ func New(input string) *Lexer {
 		input:    input,
 		position: -1,
 		pointer:  0,
+		line:     1,
 	}
 	return &l
 }
 
+// NewFile returns a lexer whose token positions name the source file.
+func NewFile(file string, input string) *Lexer {
+	l := New(input)
+	l.file = file
+	return l
+}
+
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
 	if l.pointer >= len(l.input) {
+		if l.ch != 0 || l.position < 0 {
+			l.position = l.pointer
+			l.column++
+		}
 		l.ch = 0
 		return
 	}
 	l.ch = l.input[l.pointer]
 	l.position = l.pointer
 	l.pointer++
+	l.column++
+}
+
+// pos returns the position of the current character.
+func (l *Lexer) pos() token.Position {
+	return token.Position{File: l.file, Line: l.line, Column: l.column, Offset: l.position}
 }
 
 func (l *Lexer) NextToken() token.Token {
 	l.readChar()
 	l.skipWhiteSpace()
 
+	pos := l.pos()
+	tkn := l.readToken()
+	tkn.Pos = pos
+	return tkn
+}
+
+func (l *Lexer) readToken() token.Token {
 	if l.ch == 0 {
 		return token.GetEofToken()
 	}
 
+	// Check if it's int or float iteral, a float may start with its dot
+	if token.IsDigit(l.ch) || l.ch == '.' && token.IsDigit(l.peekChar()) {
+		num := l.readNumber()
+		return token.GetNumberToken(num)
+	}
+
+	if strings.HasPrefix(l.input[l.position:], "...") {
+		l.readChar()
+		l.readChar()
+		return token.Token{TokenType: token.ELLIPSIS, Lexeme: "..."}
+	}
+
 	// Check if it's a Punctuator
 	tkn, found := token.GetPunctuatorToken(l.ch)
 	if found {
@@ -53,12 +104,6 @@ func (l *Lexer) NextToken() token.Token {
 		}
 	}
 
-	// Check if it's int or float iteral
-	if token.IsDigit(l.ch) {
-		num := l.readNumber()
-		return token.GetNumberToken(num)
-	}
-
 	// Check if it's a char literal
 	if l.ch == '\'' {
 		char := l.readCharLiteral()
@@ -102,9 +147,17 @@ func (l *Lexer) readWord() string {
 	return l.input[position:l.pointer]
 }
 
+// readNumber reads the digits, letters and dots of a numeric literal, with a
+// sign allowed right after the exponent, e in decimal and p in hex floats.
 func (l *Lexer) readNumber() string {
 	position := l.position
-	for token.IsNumber(l.peekChar()) {
+	hex := l.ch == '0' && (l.peekChar() == 'x' || l.peekChar() == 'X')
+	for {
+		ch := l.peekChar()
+		isExponent := !hex && (l.ch == 'e' || l.ch == 'E') || hex && (l.ch == 'p' || l.ch == 'P')
+		if !token.IsWordSymbol(ch) && ch != '.' && !(isExponent && (ch == '+' || ch == '-')) {
+			break
+		}
 		l.readChar()
 	}
 	return l.input[position:l.pointer]
@@ -149,8 +202,76 @@ func (l *Lexer) peekChar() byte {
 	return l.input[l.pointer]
 }
 
+func (l *Lexer) Errors() []error {
+	return l.errors
+}
+
+// skipWhiteSpace skips whitespace along with line and block comments, which
+// the lexer treats as whitespace.
 func (l *Lexer) skipWhiteSpace() {
-	for token.IsWhiteSpace(l.ch) {
+	for {
+		switch {
+		case token.IsWhiteSpace(l.ch):
+			l.readChar()
+		case l.ch == '/' && l.peekChar() == '/':
+			l.skipLineComment()
+		case l.ch == '/' && l.peekChar() == '*':
+			l.skipBlockComment()
+		case l.ch == '#' && l.skipLineMarker():
+		default:
+			return
+		}
+	}
+}
+
+// skipLineMarker skips a #line N "file" directive, such as the ones the
+// preprocessor leaves around included text, and numbers the lines after it
+// from N in the file it names. It reports whether the current # started one.
+func (l *Lexer) skipLineMarker() bool {
+	for i := l.position - 1; i >= 0 && l.input[i] != '\n'; i-- {
+		if !token.IsWhiteSpace(l.input[i]) {
+			return false
+		}
+	}
+	directive, _, _ := strings.Cut(l.input[l.position+1:], "\n")
+	directive = strings.TrimSpace(directive)
+	rest, ok := strings.CutPrefix(directive, "line")
+	if !ok {
+		return false
+	}
+	number, file, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	line, err := strconv.Atoi(number)
+	if err != nil || line < 1 {
+		return false
+	}
+	for l.peekChar() != '\n' && l.peekChar() != 0 {
+		l.readChar()
+	}
+	l.line = line - 1
+	if file = strings.Trim(strings.TrimSpace(file), `"`); file != "" {
+		l.file = file
+	}
+	l.readChar()
+	return true
+}
+
+func (l *Lexer) skipLineComment() {
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+}
+
+func (l *Lexer) skipBlockComment() {
+	pos := l.pos()
+	l.readChar()
+	l.readChar()
+	for l.ch != 0 {
+		if l.ch == '*' && l.peekChar() == '/' {
+			l.readChar()
+			l.readChar()
+			return
+		}
 		l.readChar()
 	}
+	l.errors = append(l.errors, fmt.Errorf("%s: Lexer Error, unterminated block comment", pos))
 }
@@ -7,7 +7,7 @@ import (
 )
 
 func TestPunctuatorTokens(t *testing.T) {
-	var input = `( ) [ ] { } , ; # . ~`
+	var input = `( ) [ ] { } , ; # . ~ ... ..`
 
 	expectedTokens := []token.Token{
 		{TokenType: token.LPAREN, Lexeme: "("},
@@ -21,6 +21,9 @@ func TestPunctuatorTokens(t *testing.T) {
 		{TokenType: token.PREPROC, Lexeme: "#"},
 		{TokenType: token.DOT, Lexeme: "."},
 		{TokenType: token.TILDE, Lexeme: "~"},
+		{TokenType: token.ELLIPSIS, Lexeme: "..."},
+		{TokenType: token.DOT, Lexeme: "."},
+		{TokenType: token.DOT, Lexeme: "."},
 		{TokenType: token.EOF, Lexeme: ""},
 	}
 
@@ -477,3 +480,184 @@ func TestExpressionTokens(t *testing.T) {
 		}
 	}
 }
+
+func TestComments(t *testing.T) {
+	var input = `// leading line comment
+int a = 1; // trailing comment
+/* block
+   comment */ a = a /* inline */ / 2;
+char *s = "not /* a */ comment // here";
+/**/ b /***/ ;
+// comment at end of input`
+
+	expectedTokens := []token.Token{
+		{TokenType: token.INT, Lexeme: "int"},
+		{TokenType: token.IDENTIFIER, Lexeme: "a"},
+		{TokenType: token.ASSIGN, Lexeme: "="},
+		{TokenType: token.INT_LITERAL, Lexeme: "1"},
+		{TokenType: token.SEMCOL, Lexeme: ";"},
+		{TokenType: token.IDENTIFIER, Lexeme: "a"},
+		{TokenType: token.ASSIGN, Lexeme: "="},
+		{TokenType: token.IDENTIFIER, Lexeme: "a"},
+		{TokenType: token.SLASH, Lexeme: "/"},
+		{TokenType: token.INT_LITERAL, Lexeme: "2"},
+		{TokenType: token.SEMCOL, Lexeme: ";"},
+		{TokenType: token.CHAR, Lexeme: "char"},
+		{TokenType: token.ASTER, Lexeme: "*"},
+		{TokenType: token.IDENTIFIER, Lexeme: "s"},
+		{TokenType: token.ASSIGN, Lexeme: "="},
+		{TokenType: token.STRING_LITERAL, Lexeme: "\"not /* a */ comment // here\""},
+		{TokenType: token.SEMCOL, Lexeme: ";"},
+		{TokenType: token.IDENTIFIER, Lexeme: "b"},
+		{TokenType: token.SEMCOL, Lexeme: ";"},
+		{TokenType: token.EOF, Lexeme: ""},
+	}
+
+	var l = New(input)
+
+	for i, expectedToken := range expectedTokens {
+		var tkn token.Token = l.NextToken()
+
+		if tkn.TokenType != expectedToken.TokenType {
+			t.Errorf("[%d] - Wrong TokenType, Expected - %s, got - %s", i, expectedToken.TokenType, tkn.TokenType)
+		}
+
+		if tkn.Lexeme != expectedToken.Lexeme {
+			t.Fatalf("[%d] - Wrong Lexeme, Expected - %s, got - %s", i, expectedToken.Lexeme, tkn.Lexeme)
+		}
+	}
+	if len(l.Errors()) != 0 {
+		t.Fatalf("Unexpected lexer errors: %v", l.Errors())
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	tests := []string{
+		"int a; /* never closed",
+		"/* ends with a star *",
+		"/*/",
+	}
+
+	for i, input := range tests {
+		l := New(input)
+		for tkn := l.NextToken(); tkn.TokenType != token.EOF; tkn = l.NextToken() {
+		}
+		if len(l.Errors()) != 1 {
+			t.Fatalf("[%d] - Expected 1 lexer error for %q, got %v", i, input, l.Errors())
+		}
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	var input = "int a = 1;\n  a += 2; /* x\n y */ b\n\"s\""
+
+	expected := []struct {
+		lexeme string
+		line   int
+		column int
+		offset int
+	}{
+		{"int", 1, 1, 0},
+		{"a", 1, 5, 4},
+		{"=", 1, 7, 6},
+		{"1", 1, 9, 8},
+		{";", 1, 10, 9},
+		{"a", 2, 3, 13},
+		{"+=", 2, 5, 15},
+		{"2", 2, 8, 18},
+		{";", 2, 9, 19},
+		{"b", 3, 7, 32},
+		{"\"s\"", 4, 1, 34},
+		{"", 4, 4, 37},
+	}
+
+	l := NewFile("prog.c", input)
+	for i, tt := range expected {
+		tkn := l.NextToken()
+		if tkn.Lexeme != tt.lexeme {
+			t.Fatalf("[%d] - Wrong Lexeme, Expected - %s, got - %s", i, tt.lexeme, tkn.Lexeme)
+		}
+		if tkn.Pos.Line != tt.line || tkn.Pos.Column != tt.column || tkn.Pos.Offset != tt.offset {
+			t.Errorf("[%d] - Wrong position for %q, Expected - %d:%d@%d, got - %d:%d@%d", i, tt.lexeme, tt.line, tt.column, tt.offset, tkn.Pos.Line, tkn.Pos.Column, tkn.Pos.Offset)
+		}
+		if tkn.Pos.File != "prog.c" {
+			t.Errorf("[%d] - Wrong file, Expected - prog.c, got - %s", i, tkn.Pos.File)
+		}
+	}
+
+	pos := token.Position{File: "prog.c", Line: 3, Column: 6}
+	if pos.String() != "prog.c:3:6" {
+		t.Errorf("Wrong position string, Expected - prog.c:3:6, got - %s", pos.String())
+	}
+	pos.File = ""
+	if pos.String() != "3:6" {
+		t.Errorf("Wrong position string, Expected - 3:6, got - %s", pos.String())
+	}
+}
+
+func TestNumberTokens(t *testing.T) {
+	var input = `0xFF 017 0b1010 1e-9 .5 5. 1.5e+3f 10UL 0x1.8p3 0x1e+1 1..2 s.x 08`
+
+	expectedTokens := []token.Token{
+		{TokenType: token.INT_LITERAL, Lexeme: "0xFF"},
+		{TokenType: token.INT_LITERAL, Lexeme: "017"},
+		{TokenType: token.INT_LITERAL, Lexeme: "0b1010"},
+		{TokenType: token.FLOAT_LITERAL, Lexeme: "1e-9"},
+		{TokenType: token.FLOAT_LITERAL, Lexeme: ".5"},
+		{TokenType: token.FLOAT_LITERAL, Lexeme: "5."},
+		{TokenType: token.FLOAT_LITERAL, Lexeme: "1.5e+3f"},
+		{TokenType: token.INT_LITERAL, Lexeme: "10UL"},
+		{TokenType: token.FLOAT_LITERAL, Lexeme: "0x1.8p3"},
+		{TokenType: token.INT_LITERAL, Lexeme: "0x1e"},
+		{TokenType: token.PLUS, Lexeme: "+"},
+		{TokenType: token.INT_LITERAL, Lexeme: "1"},
+		{TokenType: token.FLOAT_LITERAL, Lexeme: "1..2"},
+		{TokenType: token.IDENTIFIER, Lexeme: "s"},
+		{TokenType: token.DOT, Lexeme: "."},
+		{TokenType: token.IDENTIFIER, Lexeme: "x"},
+		{TokenType: token.INT_LITERAL, Lexeme: "08"},
+		{TokenType: token.EOF, Lexeme: ""},
+	}
+
+	var l = New(input)
+
+	for i, expectedToken := range expectedTokens {
+		var tkn token.Token = l.NextToken()
+
+		if tkn.TokenType != expectedToken.TokenType {
+			t.Errorf("[%d] - Wrong TokenType, Expected - %s, got - %s", i, expectedToken.TokenType, tkn.TokenType)
+		}
+
+		if tkn.Lexeme != expectedToken.Lexeme {
+			t.Fatalf("[%d] - Wrong Lexeme, Expected - %s, got - %s", i, expectedToken.Lexeme, tkn.Lexeme)
+		}
+	}
+}
+
+func TestLineMarkers(t *testing.T) {
+	var input = "a\n#line 1 \"inc.h\"\nb\n  # line 10\nc\nd # line 3\n"
+
+	expected := []struct {
+		lexeme string
+		file   string
+		line   int
+		column int
+	}{
+		{"a", "main.c", 1, 1},
+		{"b", "inc.h", 1, 1},
+		{"c", "inc.h", 10, 1},
+		{"d", "inc.h", 11, 1},
+		{"#", "inc.h", 11, 3},
+	}
+
+	l := NewFile("main.c", input)
+	for i, tt := range expected {
+		tkn := l.NextToken()
+		if tkn.Lexeme != tt.lexeme {
+			t.Fatalf("[%d] - Wrong Lexeme, Expected - %s, got - %s", i, tt.lexeme, tkn.Lexeme)
+		}
+		if tkn.Pos.File != tt.file || tkn.Pos.Line != tt.line || tkn.Pos.Column != tt.column {
+			t.Errorf("[%d] - Wrong position for %q, Expected - %s:%d:%d, got - %s", i, tt.lexeme, tt.file, tt.line, tt.column, tkn.Pos.String())
+		}
+	}
+}
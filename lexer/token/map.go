@@ -91,6 +91,7 @@ var TokenMap map[TokenType]string = map[TokenType]string{
 	95: "CHAR_LITERAL",
 	96: "STRING_LITERAL",
 	97: "BOOL_LITERAL",
+	98: "ELLIPSIS",
 }
 
 var PunctuatorMap map[byte]TokenType = map[byte]TokenType{
@@ -195,7 +196,7 @@ var WhiteSpaceSymbols = []byte{
 }
 
 var Datatypes = []TokenType{
-	INT, BOOL, STRING, FLOAT, CHAR, VOID, DOUBLE,
+	INT, BOOL, STRING, FLOAT, CHAR, VOID, DOUBLE, STRUCT, UNION,
 }
 
 var AssignmentOps = []TokenType{
@@ -1,6 +1,7 @@
 package token
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -9,6 +10,30 @@ type TokenType int
 type Token struct {
 	TokenType TokenType
 	Lexeme    string
+	Pos       Position
+}
+
+// Position is the location of a token in the source, Line and Column count
+// from 1 and Offset is the byte offset from the start of the input.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+	Offset int
+}
+
+// IsValid reports whether the position was set by the lexer.
+func (pos Position) IsValid() bool {
+	return pos.Line > 0
+}
+
+// String formats the position as file:line:col, leaving out the file
+// when the source was not read from one.
+func (pos Position) String() string {
+	if pos.File == "" {
+		return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", pos.File, pos.Line, pos.Column)
 }
 
 const (
@@ -107,6 +132,8 @@ const (
 	CHAR_LITERAL   TokenType = 95 // character constants
 	STRING_LITERAL TokenType = 96 // string literals
 	BOOL_LITERAL   TokenType = 97 // Boolean literal
+
+	ELLIPSIS TokenType = 98 // ... of a variadic parameter list
 )
 
 func (t TokenType) String() string {
@@ -114,11 +141,11 @@ func (t TokenType) String() string {
 }
 
 func GetEofToken() Token {
-	return Token{EOF, ""}
+	return Token{TokenType: EOF}
 }
 
 func GetIllegalToken() Token {
-	return Token{ILLEGAL, ""}
+	return Token{TokenType: ILLEGAL}
 }
 
 func GetPunctuatorToken(ch byte) (Token, bool) {
@@ -161,34 +188,25 @@ func GetIdentifierToken(word string) Token {
 	}
 }
 
+// GetNumberToken classifies a numeric literal as an integer or a floating
+// constant, its digits and suffix are checked when the parser evaluates it.
 func GetNumberToken(num string) Token {
-	var suffix = ""
-	for i := 0; i < len(num); i++ {
-		l := strings.ToLower(string(num[i]))
-		if !IsDigit(num[i]) && l == "f" || l == "u" || l == "l" {
-			suffix += l
-		} else if len(suffix) != 0 {
-			return Token{TokenType: ILLEGAL}
-		}
+	lower := strings.ToLower(num)
+	hex := strings.HasPrefix(lower, "0x")
+	tokenType := INT_LITERAL
+	if strings.Contains(num, ".") || !hex && strings.Contains(lower, "e") || hex && strings.Contains(lower, "p") {
+		tokenType = FLOAT_LITERAL
 	}
-
-	if strings.Count(num, ".") == 0 && len(suffix) <= 3 && strings.Count(suffix, "f") == 0 && suffix != "lul" {
-		return Token{
-			TokenType: INT_LITERAL,
-			Lexeme:    num,
-		}
-	} else if strings.Count(num, ".") == 1 && len(suffix) <= 1 && strings.Count(suffix, "u") == 0 {
-		return Token{
-			TokenType: FLOAT_LITERAL,
-			Lexeme:    num,
-		}
+	return Token{
+		TokenType: tokenType,
+		Lexeme:    num,
 	}
-	return Token{TokenType: ILLEGAL}
 }
 
+// GetCharToken accepts any non empty quoted char literal, escape sequences
+// and the length of the value are checked when the parser decodes it.
 func GetCharToken(char string) Token {
-	c := char[1 : len(char)-1]
-	if (char[0] == '\'' && char[len(char)-1] == '\'') && len(c) == 1 || (len(c) == 2 && c[0] == '\\') {
+	if len(char) >= 3 && char[0] == '\'' && char[len(char)-1] == '\'' {
 		return Token{
 			TokenType: CHAR_LITERAL,
 			Lexeme:    char,
@@ -198,7 +216,7 @@ func GetCharToken(char string) Token {
 }
 
 func GetStringToken(str string) Token {
-	if str[0] == '"' && str[len(str)-1] == '"' {
+	if len(str) >= 2 && str[0] == '"' && str[len(str)-1] == '"' {
 		return Token{
 			TokenType: STRING_LITERAL,
 			Lexeme:    str,
@@ -233,11 +251,6 @@ func IsDigit(ch byte) bool {
 	return ch >= '0' && ch <= '9'
 }
 
-func IsNumber(ch byte) bool {
-	lower := strings.ToLower(string(ch))
-	return IsDigit(ch) || ch == '.' || lower == "u" || lower == "f" || lower == "l"
-}
-
 func IsWordSymbol(ch byte) bool {
 	return IsLetter(ch) || IsDigit(ch) || ch == '_'
 }
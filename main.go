@@ -1,20 +1,39 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/mohamedirfanam/cynterpreter/batch"
 	"github.com/mohamedirfanam/cynterpreter/repl"
 )
 
+// includePaths collects the directories given with -I
+type includePaths []string
+
+func (paths *includePaths) String() string {
+	return strings.Join(*paths, ",")
+}
+
+func (paths *includePaths) Set(path string) error {
+	*paths = append(*paths, path)
+	return nil
+}
+
 func main() {
-	if len(os.Args) == 1 {
+	var includes includePaths
+	flag.Var(&includes, "I", "add a directory to the #include search path")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
 		fmt.Println("Cynterpreter: A C interprer")
 		fmt.Print("REPL Mode \n\n")
 
 		repl.REPL(os.Stdin, os.Stdout)
+		return
 	}
 
-	batch.HandleFile(os.Args[1])
+	batch.HandleFile(flag.Arg(0), includes...)
 }
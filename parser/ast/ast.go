@@ -2,11 +2,14 @@ package ast
 
 import (
 	"bytes"
+
+	"github.com/mohamedirfanam/cynterpreter/lexer/token"
 )
 
 type Node interface {
 	TokenLexeme() string
 	String() string
+	Pos() token.Position // position of the token the node was built from
 }
 
 type Expression interface {
@@ -31,6 +34,13 @@ func (p *Program) TokenLexeme() string {
 	return ""
 }
 
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) >= 1 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	var buf bytes.Buffer
 	for _, statement := range p.Statements {
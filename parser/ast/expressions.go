@@ -7,16 +7,24 @@ import (
 	"github.com/mohamedirfanam/cynterpreter/lexer/token"
 )
 
-// Integer literal
+// Integer literal, its type is the first of int, unsigned int, long and
+// unsigned long that its suffix allows and that can hold its value, long
+// long being as wide as long
 type IntegerLiteral struct {
-	Token token.Token
-	Value int64
+	Token    token.Token
+	Value    int64
+	Unsigned bool
+	Long     bool
 }
 
 func (il *IntegerLiteral) TokenLexeme() string {
 	return il.Token.Lexeme
 }
 
+func (il *IntegerLiteral) Pos() token.Position {
+	return il.Token.Pos
+}
+
 func (il *IntegerLiteral) expressionNode() {}
 
 func (il *IntegerLiteral) String() string {
@@ -33,22 +41,32 @@ func (cl *CharLiteral) TokenLexeme() string {
 	return cl.Token.Lexeme
 }
 
+func (cl *CharLiteral) Pos() token.Position {
+	return cl.Token.Pos
+}
+
 func (cl *CharLiteral) expressionNode() {}
 
 func (cl *CharLiteral) String() string {
 	return cl.Token.Lexeme
 }
 
-// Float literal
+// Float literal, a float suffixed constant holds a value rounded to float
+// precision, an l suffix is accepted and ignored
 type FloatLiteral struct {
-	Token token.Token
-	Value float64
+	Token  token.Token
+	Value  float64
+	Single bool // f suffix
 }
 
 func (fl *FloatLiteral) TokenLexeme() string {
 	return fl.Token.Lexeme
 }
 
+func (fl *FloatLiteral) Pos() token.Position {
+	return fl.Token.Pos
+}
+
 func (fl *FloatLiteral) expressionNode() {}
 
 func (fl *FloatLiteral) String() string {
@@ -65,6 +83,10 @@ func (sl *StringLiteral) TokenLexeme() string {
 	return sl.Token.Lexeme
 }
 
+func (sl *StringLiteral) Pos() token.Position {
+	return sl.Token.Pos
+}
+
 func (sl *StringLiteral) expressionNode() {}
 
 func (sl *StringLiteral) String() string {
@@ -81,6 +103,10 @@ func (bl *BoolLiteral) TokenLexeme() string {
 	return bl.Token.Lexeme
 }
 
+func (bl *BoolLiteral) Pos() token.Position {
+	return bl.Token.Pos
+}
+
 func (bl *BoolLiteral) expressionNode() {}
 
 func (bl *BoolLiteral) String() string {
@@ -96,6 +122,10 @@ type IdentifierExpression struct {
 func (il *IdentifierExpression) TokenLexeme() string {
 	return il.Token.Lexeme
 }
+
+func (il *IdentifierExpression) Pos() token.Position {
+	return il.Token.Pos
+}
 func (il *IdentifierExpression) expressionNode() {}
 func (il *IdentifierExpression) identifierNode() {}
 
@@ -115,6 +145,10 @@ func (infExp *InfixExpression) TokenLexeme() string {
 	return infExp.Token.Lexeme
 }
 
+func (infExp *InfixExpression) Pos() token.Position {
+	return infExp.Token.Pos
+}
+
 func (infExp *InfixExpression) expressionNode() {}
 
 func (infExp *InfixExpression) String() string {
@@ -142,6 +176,10 @@ func (prefixExp *PrefixExpression) TokenLexeme() string {
 	return prefixExp.Token.Lexeme
 }
 
+func (prefixExp *PrefixExpression) Pos() token.Position {
+	return prefixExp.Token.Pos
+}
+
 func (prefixExp *PrefixExpression) expressionNode() {}
 
 func (prefixExp *PrefixExpression) String() string {
@@ -155,6 +193,175 @@ func (prefixExp *PrefixExpression) String() string {
 	return str.String()
 }
 
+// Postfix Expression Node, the postfix increment and decrement operators
+type PostfixExpression struct {
+	Token token.Token
+	Exp   Expression
+	Op    string
+}
+
+func (postfixExp *PostfixExpression) TokenLexeme() string {
+	return postfixExp.Token.Lexeme
+}
+
+func (postfixExp *PostfixExpression) Pos() token.Position {
+	return postfixExp.Token.Pos
+}
+
+func (postfixExp *PostfixExpression) expressionNode() {}
+
+func (postfixExp *PostfixExpression) String() string {
+	return "(" + postfixExp.Exp.String() + postfixExp.Op + ")"
+}
+
+// Assignment Expression Node
+type AssignmentExpression struct {
+	Token  token.Token
+	Target IdentifierNode
+	Value  Expression
+}
+
+func (ae *AssignmentExpression) TokenLexeme() string {
+	return ae.Token.Lexeme
+}
+
+func (ae *AssignmentExpression) Pos() token.Position {
+	return ae.Token.Pos
+}
+
+func (ae *AssignmentExpression) expressionNode() {}
+
+func (ae *AssignmentExpression) String() string {
+	return "(" + ae.Target.String() + " = " + ae.Value.String() + ")"
+}
+
+// Compound Assignment Expression Node, a op= b, the target is kept once so
+// that it is evaluated once
+type CompoundAssignmentExpression struct {
+	Token  token.Token
+	Target IdentifierNode
+	Value  Expression
+}
+
+func (ce *CompoundAssignmentExpression) TokenLexeme() string {
+	return ce.Token.Lexeme
+}
+
+func (ce *CompoundAssignmentExpression) Pos() token.Position {
+	return ce.Token.Pos
+}
+
+func (ce *CompoundAssignmentExpression) expressionNode() {}
+
+func (ce *CompoundAssignmentExpression) String() string {
+	return "(" + ce.Target.String() + " " + ce.Token.Lexeme + " " + ce.Value.String() + ")"
+}
+
+// Operator returns the binary operator applied by the assignment, + for +=
+func (ce *CompoundAssignmentExpression) Operator() token.Token {
+	tkn := token.AssignmentOpMap[ce.Token.TokenType]
+	tkn.Pos = ce.Token.Pos
+	return tkn
+}
+
+// Conditional Expression Node, the ternary operator
+type ConditionalExpression struct {
+	Token       token.Token
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (ce *ConditionalExpression) TokenLexeme() string {
+	return ce.Token.Lexeme
+}
+
+func (ce *ConditionalExpression) Pos() token.Position {
+	return ce.Token.Pos
+}
+
+func (ce *ConditionalExpression) expressionNode() {}
+
+func (ce *ConditionalExpression) String() string {
+	return "(" + ce.Condition.String() + " ? " + ce.Consequence.String() + " : " + ce.Alternative.String() + ")"
+}
+
+// Dereference Expression Node
+type DereferenceExpression struct {
+	Token token.Token
+	Exp   Expression
+}
+
+func (de *DereferenceExpression) TokenLexeme() string {
+	return de.Token.Lexeme
+}
+
+func (de *DereferenceExpression) Pos() token.Position {
+	return de.Token.Pos
+}
+
+func (de *DereferenceExpression) expressionNode() {}
+func (de *DereferenceExpression) identifierNode() {}
+
+func (de *DereferenceExpression) String() string {
+	return "(*" + de.Exp.String() + ")"
+}
+
+// Member access Node, either s.member or p->member
+type MemberExpression struct {
+	Token  token.Token
+	Exp    Expression
+	Member *IdentifierExpression
+}
+
+func (me *MemberExpression) TokenLexeme() string {
+	return me.Token.Lexeme
+}
+
+func (me *MemberExpression) Pos() token.Position {
+	return me.Token.Pos
+}
+
+func (me *MemberExpression) expressionNode() {}
+func (me *MemberExpression) identifierNode() {}
+
+func (me *MemberExpression) IsArrow() bool {
+	return me.Token.TokenType == token.ARROW
+}
+
+func (me *MemberExpression) String() string {
+	return me.Exp.String() + me.Token.Lexeme + me.Member.Value
+}
+
+// Initializer list Node, {1, 2} for struct values
+type InitializerList struct {
+	Token  token.Token
+	Values []Expression
+}
+
+func (il *InitializerList) TokenLexeme() string {
+	return il.Token.Lexeme
+}
+
+func (il *InitializerList) Pos() token.Position {
+	return il.Token.Pos
+}
+
+func (il *InitializerList) expressionNode() {}
+
+func (il *InitializerList) String() string {
+	var str strings.Builder
+	str.WriteString("{")
+	for i, val := range il.Values {
+		str.WriteString(val.String())
+		if i < len(il.Values)-1 {
+			str.WriteString(", ")
+		}
+	}
+	str.WriteString("}")
+	return str.String()
+}
+
 // Call Expression Node
 type CallExpression struct {
 	Token    token.Token
@@ -166,6 +373,10 @@ func (ce *CallExpression) TokenLexeme() string {
 	return ce.Token.Lexeme
 }
 
+func (ce *CallExpression) Pos() token.Position {
+	return ce.Token.Pos
+}
+
 func (ce *CallExpression) expressionNode() {}
 
 func (ce *CallExpression) String() string {
@@ -182,11 +393,12 @@ func (ce *CallExpression) String() string {
 	return str.String()
 }
 
-// Function Literal Node
+// Function Literal Node, the Block of a prototype is nil
 type FunctionLiteral struct {
 	Token    token.Token
 	Function *IdentifierExpression
 	Params   []*Parameter
+	Variadic bool // the parameter list ends with ...
 	Block    *Block
 }
 
@@ -194,6 +406,10 @@ func (fl *FunctionLiteral) TokenLexeme() string {
 	return fl.Token.Lexeme
 }
 
+func (fl *FunctionLiteral) Pos() token.Position {
+	return fl.Token.Pos
+}
+
 func (fl *FunctionLiteral) expressionNode() {}
 
 func (fl *FunctionLiteral) String() string {
@@ -205,40 +421,97 @@ func (fl *FunctionLiteral) String() string {
 			str.WriteString(",")
 		}
 	}
+	if fl.Variadic {
+		if len(fl.Params) > 0 {
+			str.WriteString(",")
+		}
+		str.WriteString("...")
+	}
 	str.WriteString(")")
+	if fl.Block == nil {
+		return str.String()
+	}
 	str.WriteString(fl.Block.String())
 	return str.String()
 }
 
+// Parameter of a function, the Identifier may be nil in a prototype. An
+// array parameter is declared as a pointer to its first element, Array and
+// Length only keep how it was written.
 type Parameter struct {
 	Token      token.Token
 	Type       token.TokenType
+	TypeName   string
+	Pointers   int
 	Identifier *IdentifierExpression
+	Array      bool
+	Length     int // -1 for name[]
 }
 
 func (param Parameter) TokenLexeme() string {
 	return param.Token.Lexeme
 }
+
+func (param Parameter) Pos() token.Position {
+	return param.Token.Pos
+}
 func (param Parameter) String() string {
-	return param.TokenLexeme() + " " + param.Identifier.String()
+	if param.Identifier == nil {
+		return param.TypeString()
+	}
+	typeName := param.TokenLexeme() + " "
+	if param.TypeName != "" {
+		typeName += param.TypeName + " "
+	}
+	if !param.Array {
+		return typeName + strings.Repeat("*", param.Pointers) + param.Identifier.String()
+	}
+	length := ""
+	if param.Length >= 0 {
+		length = fmt.Sprint(param.Length)
+	}
+	return typeName + strings.Repeat("*", param.Pointers-1) + param.Identifier.String() + "[" + length + "]"
+}
+
+// TypeString spells the type of the parameter as in a prototype, int * or
+// struct Point.
+func (param Parameter) TypeString() string {
+	typeName := param.TokenLexeme()
+	if param.TypeName != "" {
+		typeName += " " + param.TypeName
+	}
+	if param.Pointers > 0 {
+		typeName += " " + strings.Repeat("*", param.Pointers)
+	}
+	return typeName
 }
 
-// Array Declaration Node
+// Array Declaration Node, Length is the outermost dimension and Dims the
+// inner ones of a multi-dimensional array, int m[2][3] has Length 2 and
+// Dims [3].
 type ArrayDeclaration struct {
 	Token     token.Token
 	Type      token.TokenType
 	Identifer IdentifierExpression
 	Length    int
+	Dims      []int
 	Literal   []Expression
 }
 
 func (arr ArrayDeclaration) TokenLexeme() string {
 	return arr.Token.Lexeme
 }
+
+func (arr ArrayDeclaration) Pos() token.Position {
+	return arr.Token.Pos
+}
 func (arr ArrayDeclaration) expressionNode() {}
 func (arr ArrayDeclaration) String() string {
 	var str strings.Builder
 	str.WriteString(arr.Identifer.Value + "[" + fmt.Sprint(arr.Length) + "]")
+	for _, dim := range arr.Dims {
+		str.WriteString("[" + fmt.Sprint(dim) + "]")
+	}
 	str.WriteString(" =  {")
 	for i, lit := range arr.Literal {
 		str.WriteString(lit.String())
@@ -250,21 +523,24 @@ func (arr ArrayDeclaration) String() string {
 	return str.String()
 }
 
-// Array index Node
+// Array index Node, Left is any expression giving an array, string or
+// pointer, m[i][j] indexes m[i] with j.
 type ArrayExpression struct {
-	Token     token.Token
-	Identifer *IdentifierExpression
-	Index     Expression
+	Token token.Token
+	Left  Expression
+	Index Expression
 }
 
 func (arr ArrayExpression) TokenLexeme() string {
 	return arr.Token.Lexeme
 }
+
+func (arr ArrayExpression) Pos() token.Position {
+	return arr.Token.Pos
+}
 func (arr ArrayExpression) expressionNode() {}
 func (arr ArrayExpression) identifierNode() {}
 
 func (arr ArrayExpression) String() string {
-	var str strings.Builder
-	str.WriteString(arr.Identifer.Value + "[" + fmt.Sprint(arr.Index) + "]")
-	return str.String()
+	return arr.Left.String() + "[" + fmt.Sprint(arr.Index) + "]"
 }
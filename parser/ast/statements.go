@@ -16,49 +16,131 @@ func (es *ExpressionStatement) TokenLexeme() string {
 	return es.Token.Lexeme
 }
 
+func (es *ExpressionStatement) Pos() token.Position {
+	return es.Token.Pos
+}
+
 func (es *ExpressionStatement) statementNode() {}
 
 func (es *ExpressionStatement) String() string {
 	return es.Expression.String()
 }
 
-// Declaration Statement
+// Declaration Statement, declares one or more variables of the same base
+// type, each declarator adding its own pointers and initializer
 type DeclarationStatement struct {
-	Token      token.Token
-	Type       token.TokenType
-	Identifier *IdentifierExpression
-	Literal    Expression
+	Token       token.Token
+	Type        token.TokenType
+	TypeName    string // struct or union tag
+	Declarators []*Declarator
 }
 
 func (ds *DeclarationStatement) TokenLexeme() string {
 	return ds.Token.Lexeme
 }
 
-func (ds *DeclarationStatement) statementNode()           {}
-func (ds *DeclarationStatement) initializationStatement() {}
+func (ds *DeclarationStatement) Pos() token.Position {
+	return ds.Token.Pos
+}
+
+func (ds *DeclarationStatement) statementNode() {}
 
 func (ds *DeclarationStatement) String() string {
 	var str strings.Builder
 	str.WriteString(ds.TokenLexeme() + " ")
+	if ds.TypeName != "" {
+		str.WriteString(ds.TypeName + " ")
+	}
+	for i, d := range ds.Declarators {
+		if i > 0 {
+			str.WriteString(", ")
+		}
+		str.WriteString(d.String())
+	}
+	return str.String()
+}
+
+// Declarator is a single name introduced by a declaration statement
+type Declarator struct {
+	Pointers   int
+	Identifier *IdentifierExpression
+	Literal    Expression
+}
 
-	if _, ok := ds.Literal.(*FunctionLiteral); ok {
-		str.WriteString(ds.Literal.String())
-	} else if ds.Literal != nil {
-		str.WriteString(ds.Identifier.Value)
-		str.WriteString(" = " + ds.Literal.String())
+func (d *Declarator) String() string {
+	var str strings.Builder
+	str.WriteString(strings.Repeat("*", d.Pointers))
+	if _, ok := d.Literal.(*FunctionLiteral); ok {
+		str.WriteString(d.Literal.String())
+	} else if d.Literal != nil {
+		str.WriteString(d.Identifier.Value)
+		str.WriteString(" = " + d.Literal.String())
 	} else {
-		str.WriteString(ds.Identifier.Value)
+		str.WriteString(d.Identifier.Value)
+	}
+	return str.String()
+}
+
+// Struct or union type declaration
+type StructDeclaration struct {
+	Token   token.Token
+	Name    *IdentifierExpression
+	Members []*DeclarationStatement
+}
+
+func (sd *StructDeclaration) TokenLexeme() string {
+	return sd.Token.Lexeme
+}
+
+func (sd *StructDeclaration) Pos() token.Position {
+	return sd.Token.Pos
+}
+
+func (sd *StructDeclaration) statementNode() {}
+
+func (sd *StructDeclaration) IsUnion() bool {
+	return sd.Token.TokenType == token.UNION
+}
+
+func (sd *StructDeclaration) String() string {
+	var str strings.Builder
+	str.WriteString(sd.TokenLexeme() + " " + sd.Name.Value + " {\n")
+	for _, member := range sd.Members {
+		str.WriteString("\t" + member.String() + ";\n")
 	}
+	str.WriteString("}")
 	return str.String()
 }
 
-// Block statement
+// Block statement, Implicit marks the body of a control-flow statement
+// written as a single statement without braces
+// Block is a brace enclosed list of statements, or the single statement
+// body of a control statement written without braces when Implicit.
 type Block struct {
+	Token      token.Token
 	Statements []Statement
+	Implicit   bool
+}
+
+func (blk Block) TokenLexeme() string {
+	return blk.Token.Lexeme
+}
+
+func (blk Block) Pos() token.Position {
+	return blk.Token.Pos
 }
 
+func (blk Block) statementNode() {}
+
 func (blk Block) String() string {
 	var str strings.Builder
+	if blk.Implicit {
+		for _, stmnt := range blk.Statements {
+			str.WriteString(stmnt.String())
+		}
+		str.WriteString(";\n")
+		return str.String()
+	}
 	str.WriteString("{\n")
 	for _, stmnt := range blk.Statements {
 		str.WriteString("\t" + stmnt.String() + ";\n")
@@ -77,6 +159,10 @@ func (rs *ReturnStatement) TokenLexeme() string {
 	return rs.Token.Lexeme
 }
 
+func (rs *ReturnStatement) Pos() token.Position {
+	return rs.Token.Pos
+}
+
 func (rs *ReturnStatement) statementNode() {}
 
 func (rs *ReturnStatement) String() string {
@@ -88,6 +174,7 @@ type IfStatement struct {
 	Token     token.Token
 	Condition Expression
 	Block     *Block
+	ElseIf    *IfStatement
 	ElseBlock *Block
 }
 
@@ -95,6 +182,10 @@ func (ifs *IfStatement) TokenLexeme() string {
 	return ifs.Token.Lexeme
 }
 
+func (ifs *IfStatement) Pos() token.Position {
+	return ifs.Token.Pos
+}
+
 func (ifs *IfStatement) statementNode() {}
 
 func (ifs *IfStatement) String() string {
@@ -102,7 +193,10 @@ func (ifs *IfStatement) String() string {
 	str.WriteString("if (")
 	str.WriteString(ifs.Condition.String() + ")")
 	str.WriteString(ifs.Block.String())
-	if ifs.ElseBlock != nil {
+	if ifs.ElseIf != nil {
+		str.WriteString("else ")
+		str.WriteString(ifs.ElseIf.String())
+	} else if ifs.ElseBlock != nil {
 		str.WriteString("else ")
 		str.WriteString(ifs.ElseBlock.String())
 	}
@@ -118,21 +212,30 @@ type AssignmentStatement struct {
 	Token      token.Token
 	Identifier IdentifierNode
 	Literal    Expression
+	// Op is the = token or the one of a compound assignment such as +=
+	Op token.Token
 }
 
 func (as *AssignmentStatement) TokenLexeme() string {
 	return as.Token.Lexeme
 }
 
-func (as *AssignmentStatement) statementNode()           {}
-func (as *AssignmentStatement) initializationStatement() {}
+func (as *AssignmentStatement) Pos() token.Position {
+	return as.Token.Pos
+}
+
+func (as *AssignmentStatement) statementNode() {}
 
 func (as *AssignmentStatement) String() string {
 	var str strings.Builder
 
 	str.WriteString(as.Identifier.String())
 	if as.Literal != nil {
-		str.WriteString(" = " + as.Literal.String())
+		op := as.Op.Lexeme
+		if op == "" {
+			op = "="
+		}
+		str.WriteString(" " + op + " " + as.Literal.String())
 	}
 	return str.String()
 }
@@ -148,6 +251,10 @@ func (wl *WhileStatement) TokenLexeme() string {
 	return wl.Token.Lexeme
 }
 
+func (wl *WhileStatement) Pos() token.Position {
+	return wl.Token.Pos
+}
+
 func (wl *WhileStatement) statementNode() {}
 
 func (wl *WhileStatement) String() string {
@@ -158,16 +265,37 @@ func (wl *WhileStatement) String() string {
 	return str.String()
 }
 
-// For loop
-type InitializationStatement interface {
-	Statement
-	initializationStatement()
+// Do while loop
+type DoWhileStatement struct {
+	Token     token.Token
+	Block     *Block
+	Condition Expression
+}
+
+func (dw *DoWhileStatement) TokenLexeme() string {
+	return dw.Token.Lexeme
+}
+
+func (dw *DoWhileStatement) Pos() token.Position {
+	return dw.Token.Pos
 }
+
+func (dw *DoWhileStatement) statementNode() {}
+
+func (dw *DoWhileStatement) String() string {
+	var str strings.Builder
+	str.WriteString("do ")
+	str.WriteString(dw.Block.String())
+	str.WriteString("while (" + dw.Condition.String() + ")")
+	return str.String()
+}
+
+// For loop, any of the three clauses can be left out and is then nil
 type ForStatement struct {
-	Token token.Token
-	InitializationStatement
+	Token     token.Token
+	Init      Statement
 	Condition Expression
-	Increment *AssignmentStatement
+	Increment Statement
 	Block     *Block
 }
 
@@ -175,14 +303,125 @@ func (fs *ForStatement) TokenLexeme() string {
 	return fs.Token.Lexeme
 }
 
+func (fs *ForStatement) Pos() token.Position {
+	return fs.Token.Pos
+}
+
 func (fs *ForStatement) statementNode() {}
 
 func (fs *ForStatement) String() string {
 	var str strings.Builder
 	str.WriteString("for (")
-	str.WriteString(fs.InitializationStatement.String() + "; ")
-	str.WriteString(fs.Condition.String() + ";")
-	str.WriteString(fs.Increment.String() + ")")
+	if fs.Init != nil {
+		str.WriteString(fs.Init.String())
+	}
+	str.WriteString("; ")
+	if fs.Condition != nil {
+		str.WriteString(fs.Condition.String())
+	}
+	str.WriteString(";")
+	if fs.Increment != nil {
+		str.WriteString(fs.Increment.String())
+	}
+	str.WriteString(")")
 	str.WriteString(fs.Block.String())
 	return str.String()
 }
+
+// Switch statement
+type SwitchStatement struct {
+	Token     token.Token
+	Condition Expression
+	Cases     []*CaseClause
+}
+
+func (ss *SwitchStatement) TokenLexeme() string {
+	return ss.Token.Lexeme
+}
+
+func (ss *SwitchStatement) Pos() token.Position {
+	return ss.Token.Pos
+}
+
+func (ss *SwitchStatement) statementNode() {}
+
+func (ss *SwitchStatement) String() string {
+	var str strings.Builder
+	str.WriteString("switch (")
+	str.WriteString(ss.Condition.String() + "){\n")
+	for _, clause := range ss.Cases {
+		str.WriteString(clause.String())
+	}
+	str.WriteString("}\n")
+	return str.String()
+}
+
+// Case clause of a switch, Value is nil for the default clause
+type CaseClause struct {
+	Token      token.Token
+	Value      Expression
+	Statements []Statement
+}
+
+func (cc *CaseClause) TokenLexeme() string {
+	return cc.Token.Lexeme
+}
+
+func (cc *CaseClause) Pos() token.Position {
+	return cc.Token.Pos
+}
+
+func (cc *CaseClause) IsDefault() bool {
+	return cc.Value == nil
+}
+
+func (cc *CaseClause) String() string {
+	var str strings.Builder
+	if cc.IsDefault() {
+		str.WriteString("default:\n")
+	} else {
+		str.WriteString("case " + cc.Value.String() + ":\n")
+	}
+	for _, stmnt := range cc.Statements {
+		str.WriteString("\t" + stmnt.String() + ";\n")
+	}
+	return str.String()
+}
+
+// Break statement
+type BreakStatement struct {
+	Token token.Token
+}
+
+func (bs *BreakStatement) TokenLexeme() string {
+	return bs.Token.Lexeme
+}
+
+func (bs *BreakStatement) Pos() token.Position {
+	return bs.Token.Pos
+}
+
+func (bs *BreakStatement) statementNode() {}
+
+func (bs *BreakStatement) String() string {
+	return "break"
+}
+
+// Continue statement
+type ContinueStatement struct {
+	Token token.Token
+}
+
+func (cs *ContinueStatement) TokenLexeme() string {
+	return cs.Token.Lexeme
+}
+
+func (cs *ContinueStatement) Pos() token.Position {
+	return cs.Token.Pos
+}
+
+func (cs *ContinueStatement) statementNode() {}
+
+func (cs *ContinueStatement) String() string {
+	return "continue"
+}
@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/mohamedirfanam/cynterpreter/lexer/token"
 	"github.com/mohamedirfanam/cynterpreter/parser/ast"
@@ -10,10 +13,17 @@ import (
 
 const (
 	LOWEST        = iota
+	COMMA         // ,
+	ASSIGN        // = += -= ...
+	TERNARY       // ?:
 	OR            // ||
 	AND           // &&
+	BITOR         // |
+	BITXOR        // ^
+	BITAND        // &
 	EQUALS        // ==
 	LESSGREATER   // < > <= >=
+	SHIFT         // << >>
 	SUMSUB        // + -
 	PRODUCTDEVIDE // * /
 	PREFIX        // -x
@@ -22,27 +32,44 @@ const (
 )
 
 var precedences = map[token.TokenType]int{
-	token.PLUS:    SUMSUB,
-	token.MINUS:   SUMSUB,
-	token.ASTER:   PRODUCTDEVIDE,
-	token.SLASH:   PRODUCTDEVIDE,
-	token.PERCENT: PRODUCTDEVIDE,
-	token.EQ:      EQUALS,
-	token.NE:      EQUALS,
-	token.LT:      LESSGREATER,
-	token.LE:      LESSGREATER,
-	token.GT:      LESSGREATER,
-	token.GE:      LESSGREATER,
-	token.LPAREN:  CALL,
-	token.LBRACK:  ARRAY,
-	token.AND:     AND,
-	token.OR:      OR,
+	token.PLUS:     SUMSUB,
+	token.MINUS:    SUMSUB,
+	token.ASTER:    PRODUCTDEVIDE,
+	token.SLASH:    PRODUCTDEVIDE,
+	token.PERCENT:  PRODUCTDEVIDE,
+	token.EQ:       EQUALS,
+	token.NE:       EQUALS,
+	token.LT:       LESSGREATER,
+	token.LE:       LESSGREATER,
+	token.GT:       LESSGREATER,
+	token.GE:       LESSGREATER,
+	token.LPAREN:   CALL,
+	token.LBRACK:   ARRAY,
+	token.DOT:      ARRAY,
+	token.ARROW:    ARRAY,
+	token.AND:      AND,
+	token.OR:       OR,
+	token.PIPE:     BITOR,
+	token.XOR:      BITXOR,
+	token.AMP:      BITAND,
+	token.LSHIFT:   SHIFT,
+	token.RSHIFT:   SHIFT,
+	token.INCR:     ARRAY,
+	token.DECR:     ARRAY,
+	token.QUESTION: TERNARY,
+	token.COMMA:    COMMA,
+}
+
+func init() {
+	for _, op := range token.AssignmentOps {
+		precedences[op] = ASSIGN
+	}
 }
 
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefixFunc, ok := p.prefixParseFuncs[p.curToken.TokenType]
 	if !ok {
-		p.errors = append(p.errors, fmt.Errorf("no valid prefix parsing function found for token %s", p.curToken.TokenType))
+		p.errorf(p.curToken.Pos, "no valid prefix parsing function found for token %s", p.curToken.TokenType)
 		return nil
 	}
 	leftExp := prefixFunc()
@@ -51,7 +78,7 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 		p.nextToken()
 		infixFunc, ok := p.infixParseFuncs[p.curToken.TokenType]
 		if !ok {
-			p.errors = append(p.errors, fmt.Errorf("no valid infix parsing function found for token %s", p.curToken.TokenType))
+			p.errorf(p.curToken.Pos, "no valid infix parsing function found for token %s", p.curToken.TokenType)
 			return nil
 		}
 
@@ -61,21 +88,105 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 }
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
-	val, err := strconv.ParseInt(p.curToken.Lexeme, 10, 64)
+	lit := &ast.IntegerLiteral{Token: p.curToken}
+	val, err := parseIntegerConstant(lit)
 	if err != nil {
-		p.errors = append(p.errors, fmt.Errorf("parser Error: Error parsring integer literal %s", p.curToken.Lexeme))
+		p.errorf(p.curToken.Pos, "parser Error: %s", err)
 		return nil
 	}
-	return &ast.IntegerLiteral{
-		Token: p.curToken,
-		Value: val,
+	lit.Value = val
+	return lit
+}
+
+var integerBases = map[string]int{"0x": 16, "0X": 16, "0b": 2, "0B": 2}
+
+// parseIntegerConstant evaluates a decimal, octal, hex or binary constant and
+// records the type its suffix and value select on lit. Constants are 64 bit,
+// only a hex, octal, binary or unsigned constant may use the full unsigned
+// range.
+func parseIntegerConstant(lit *ast.IntegerLiteral) (int64, error) {
+	num := lit.Token.Lexeme
+	base, digits, kind := 10, num, "decimal"
+	if b, ok := integerBases[num[:min(2, len(num))]]; ok {
+		base, digits = b, num[2:]
+		kind = map[int]string{16: "hex", 2: "binary"}[b]
+	} else if len(num) > 1 && num[0] == '0' {
+		base, kind = 8, "octal"
+	}
+	n := 0
+	for n < len(digits) && isDigitOf(digits[n], base) {
+		n++
+	}
+	digits, suffix := digits[:n], digits[n:]
+	if suffix != "" && base <= 8 && token.IsDigit(suffix[0]) {
+		return 0, fmt.Errorf("invalid digit %q in %s constant %s", suffix[0], kind, num)
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("%s constant %s has no digits", kind, num)
+	}
+	unsigned, long, ok := integerSuffix(suffix)
+	if !ok {
+		return 0, fmt.Errorf("invalid suffix %q on integer constant %s", suffix, num)
 	}
+	val, err := strconv.ParseUint(digits, base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("integer constant %s is too large for its type", num)
+	}
+	// a decimal constant without u stays signed, the others may also take
+	// the unsigned type of each width
+	signedOnly := base == 10 && !unsigned
+	switch {
+	case !long && !unsigned && val <= math.MaxInt32:
+	case !long && !signedOnly && val <= math.MaxUint32:
+		lit.Unsigned = true
+	case !unsigned && val <= math.MaxInt64:
+		lit.Long = true
+	case !signedOnly:
+		lit.Unsigned, lit.Long = true, true
+	default:
+		return 0, fmt.Errorf("integer constant %s is too large for its type", num)
+	}
+	return int64(val), nil
+}
+
+// integerSuffix reports whether suffix is one of u, l, ll or a combination of
+// u with l or ll, in any order and case, and which of them it has.
+func integerSuffix(suffix string) (unsigned bool, long bool, ok bool) {
+	rest := suffix
+	if len(rest) > 0 && (rest[0] == 'u' || rest[0] == 'U') {
+		unsigned, rest = true, rest[1:]
+	}
+	switch {
+	case strings.HasPrefix(rest, "ll"), strings.HasPrefix(rest, "LL"):
+		long, rest = true, rest[2:]
+	case strings.HasPrefix(rest, "l"), strings.HasPrefix(rest, "L"):
+		long, rest = true, rest[1:]
+	}
+	if !unsigned && (rest == "u" || rest == "U") {
+		unsigned, rest = true, ""
+	}
+	return unsigned, long, rest == ""
+}
+
+func isDigitOf(ch byte, base int) bool {
+	switch base {
+	case 2:
+		return ch == '0' || ch == '1'
+	case 8:
+		return isOctalDigit(ch)
+	case 16:
+		return isHexDigit(ch)
+	}
+	return token.IsDigit(ch)
 }
 
 func (p *Parser) parseCharLiteral() ast.Expression {
-	val, err := strconv.Unquote(p.curToken.Lexeme)
-	if err != nil || len(val) > 1 {
-		p.errors = append(p.errors, fmt.Errorf("parser Error: Error parsring char literal %s", p.curToken.Lexeme))
+	val, ok := p.decodeLiteral(p.curToken)
+	if !ok {
+		return nil
+	}
+	if len(val) != 1 {
+		p.errorf(p.curToken.Pos, "parser Error: char literal %s must hold exactly one character", p.curToken.Lexeme)
 		return nil
 	}
 	return &ast.CharLiteral{
@@ -85,21 +196,55 @@ func (p *Parser) parseCharLiteral() ast.Expression {
 }
 
 func (p *Parser) parseFloatLiteral() ast.Expression {
-	val, err := strconv.ParseFloat(p.curToken.Lexeme, 64)
+	lit := &ast.FloatLiteral{Token: p.curToken}
+	val, err := parseFloatConstant(lit)
 	if err != nil {
-		p.errors = append(p.errors, fmt.Errorf("parser Error: Error parsring float  literal %s", p.curToken.Lexeme))
+		p.errorf(p.curToken.Pos, "parser Error: %s", err)
 		return nil
 	}
-	return &ast.FloatLiteral{
-		Token: p.curToken,
-		Value: val,
+	lit.Value = val
+	return lit
+}
+
+// parseFloatConstant evaluates a decimal or hex floating constant and records
+// an f suffix on lit.
+func parseFloatConstant(lit *ast.FloatLiteral) (float64, error) {
+	num := lit.Token.Lexeme
+	body := num
+	switch num[len(num)-1] {
+	case 'f', 'F':
+		lit.Single, body = true, num[:len(num)-1]
+	case 'l', 'L':
+		body = num[:len(num)-1]
+	}
+	hex := strings.HasPrefix(body, "0x") || strings.HasPrefix(body, "0X")
+	for i := 0; i < len(body); i++ {
+		ch := body[i]
+		valid := token.IsDigit(ch) || ch == '.' || ch == '+' || ch == '-' ||
+			!hex && (ch == 'e' || ch == 'E') || hex && (isHexDigit(ch) || ch == 'p' || ch == 'P' || i == 1)
+		if !valid {
+			return 0, fmt.Errorf("invalid suffix %q on floating constant %s", body[i:]+num[len(body):], num)
+		}
+	}
+	if hex && !strings.ContainsAny(body, "pP") {
+		return 0, fmt.Errorf("hex floating constant %s requires an exponent", num)
+	}
+	val, err := strconv.ParseFloat(body, 64)
+	if errors.Is(err, strconv.ErrRange) || lit.Single && math.Abs(val) > math.MaxFloat32 {
+		return 0, fmt.Errorf("floating constant %s is out of range", num)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid floating constant %s", num)
 	}
+	if lit.Single {
+		val = float64(float32(val))
+	}
+	return val, nil
 }
 
 func (p *Parser) parseStringLiteral() ast.Expression {
-	val, err := strconv.Unquote(p.curToken.Lexeme)
-	if err != nil {
-		p.errors = append(p.errors, fmt.Errorf("parser Error: Error parsing String literal %s", p.curToken.Lexeme))
+	val, ok := p.decodeLiteral(p.curToken)
+	if !ok {
 		return nil
 	}
 	return &ast.StringLiteral{
@@ -108,6 +253,92 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	}
 }
 
+// decodeLiteral strips the quotes of a char or string literal and decodes
+// its escape sequences the way C does, an invalid escape is reported at its
+// position in the literal.
+func (p *Parser) decodeLiteral(tkn token.Token) (string, bool) {
+	lit := tkn.Lexeme
+	var val strings.Builder
+	for i := 1; i < len(lit)-1; i++ {
+		if lit[i] != '\\' {
+			val.WriteByte(lit[i])
+			continue
+		}
+		ch, n, err := decodeEscape(lit[i : len(lit)-1])
+		if err != nil {
+			pos := tkn.Pos
+			pos.Column += i
+			pos.Offset += i
+			p.errorf(pos, "parser Error: %s in literal %s", err, lit)
+			return "", false
+		}
+		val.WriteByte(ch)
+		i += n - 1
+	}
+	return val.String(), true
+}
+
+var simpleEscapes = map[byte]byte{
+	'\'': '\'', '"': '"', '?': '?', '\\': '\\',
+	'a': '\a', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v',
+}
+
+// decodeEscape decodes the escape sequence at the start of s, which begins
+// with the backslash, and returns its value and length.
+func decodeEscape(s string) (byte, int, error) {
+	if len(s) < 2 {
+		return 0, 0, fmt.Errorf("incomplete escape sequence")
+	}
+	if ch, ok := simpleEscapes[s[1]]; ok {
+		return ch, 2, nil
+	}
+	switch {
+	case isOctalDigit(s[1]):
+		n, val := 1, 0
+		for ; n < len(s) && n <= 3 && isOctalDigit(s[n]); n++ {
+			val = val*8 + int(s[n]-'0')
+		}
+		if val > 0xFF {
+			return 0, 0, fmt.Errorf("octal escape sequence %s out of range", s[:n])
+		}
+		return byte(val), n, nil
+	case s[1] == 'x':
+		n, val := 2, 0
+		for ; n < len(s) && isHexDigit(s[n]); n++ {
+			val = val*16 + hexValue(s[n])
+			if val > 0xFF {
+				for n < len(s) && isHexDigit(s[n]) {
+					n++
+				}
+				return 0, 0, fmt.Errorf("hex escape sequence %s out of range", s[:n])
+			}
+		}
+		if n == 2 {
+			return 0, 0, fmt.Errorf("\\x used with no following hex digits")
+		}
+		return byte(val), n, nil
+	}
+	return 0, 0, fmt.Errorf("unknown escape sequence \\%c", s[1])
+}
+
+func isOctalDigit(ch byte) bool {
+	return '0' <= ch && ch <= '7'
+}
+
+func isHexDigit(ch byte) bool {
+	return token.IsDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+func hexValue(ch byte) int {
+	switch {
+	case token.IsDigit(ch):
+		return int(ch - '0')
+	case 'a' <= ch && ch <= 'f':
+		return int(ch-'a') + 10
+	}
+	return int(ch-'A') + 10
+}
+
 func (p *Parser) parseBoolLiteral() ast.Expression {
 	var val bool
 	if p.curToken.Lexeme == "true" {
@@ -143,11 +374,11 @@ func (p *Parser) parseCallArgs() []ast.Expression {
 	if p.curTokenIs(token.RPAREN) {
 		return args
 	}
-	args = append(args, p.parseExpression(LOWEST))
+	args = append(args, p.parseExpression(COMMA))
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
+		args = append(args, p.parseExpression(COMMA))
 	}
 	if !p.expectPeekToken(token.RPAREN) {
 		return nil
@@ -169,15 +400,31 @@ func (p *Parser) parseInfixExpression(leftExp ast.Expression) ast.Expression {
 	return exp
 }
 
-func getOpInfixExpression(ident ast.Expression, expr ast.Expression, opType token.TokenType) ast.Expression {
-	tkn := token.AssignmentOpMap[opType]
-	exp := &ast.InfixExpression{
-		Token:    tkn,
-		LeftExp:  ident,
-		Op:       tkn.Lexeme,
-		RightExp: expr,
+// parseAssignmentExpression parses an assignment to target, the value is
+// parsed one level below ASSIGN so that a = b = c nests to the right. Compound
+// assignments keep their target once, a += b is not expanded to a = a + b so
+// that side effects in a happen once.
+func (p *Parser) parseAssignmentExpression(target ast.Expression) ast.Expression {
+	ident, ok := target.(ast.IdentifierNode)
+	if !ok {
+		p.errorf(p.curToken.Pos, "expression %s is not assignable", target)
+		return nil
+	}
+	tkn := p.curToken
+	p.nextToken()
+	value := p.parseExpression(ASSIGN - 1)
+	if tkn.TokenType != token.ASSIGN {
+		return &ast.CompoundAssignmentExpression{
+			Token:  tkn,
+			Target: ident,
+			Value:  value,
+		}
+	}
+	return &ast.AssignmentExpression{
+		Token:  tkn,
+		Target: ident,
+		Value:  value,
 	}
-	return exp
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
@@ -198,47 +445,144 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 	return exp
 }
 
+func (p *Parser) parsePostfixExpression(exp ast.Expression) ast.Expression {
+	return &ast.PostfixExpression{
+		Token: p.curToken,
+		Exp:   exp,
+		Op:    p.curToken.Lexeme,
+	}
+}
+
+// parseConditionalExpression parses the ternary operator, the alternative is
+// parsed one level below TERNARY so that a ? b : c ? d : e nests to the right.
+func (p *Parser) parseConditionalExpression(condition ast.Expression) ast.Expression {
+	exp := &ast.ConditionalExpression{
+		Token:     p.curToken,
+		Condition: condition,
+	}
+	p.nextToken()
+	exp.Consequence = p.parseExpression(LOWEST)
+	if !p.expectPeekToken(token.COLON) {
+		return nil
+	}
+	p.nextToken()
+	exp.Alternative = p.parseExpression(TERNARY - 1)
+	return exp
+}
+
+func (p *Parser) parseDereferenceExpression() ast.Expression {
+	exp := &ast.DereferenceExpression{
+		Token: p.curToken,
+	}
+	p.nextToken()
+	exp.Exp = p.parseExpression(PREFIX)
+	return exp
+}
+
+// parseFunctionLiteral parses the parameter list of a function and its body,
+// a prototype ends with the parameter list and has no body.
 func (p *Parser) parseFunctionLiteral(funcIdentifier *ast.IdentifierExpression) *ast.FunctionLiteral {
 	expr := &ast.FunctionLiteral{
 		Token:    p.curToken,
 		Function: funcIdentifier,
 	}
 	p.nextToken()
-	expr.Params = p.parseFunctionParams()
-	p.expectPeekToken(token.LBRACE)
+	expr.Params, expr.Variadic = p.parseFunctionParams()
+	if p.peekTokenIs(token.SEMCOL) || p.peekTokenIs(token.COMMA) {
+		return expr
+	}
+	if !p.expectPeekToken(token.LBRACE) {
+		return expr
+	}
+	for _, param := range expr.Params {
+		if param != nil && param.Identifier == nil {
+			p.errorf(param.Pos(), "parameter name omitted in the definition of function %s", funcIdentifier)
+		}
+	}
+	if expr.Variadic {
+		p.errorf(expr.Pos(), "variadic function %s can only be declared, defining one is not supported", funcIdentifier)
+	}
+	// a break or continue never reaches out of a function body
+	breakDepth, loopDepth := p.breakDepth, p.loopDepth
+	p.breakDepth, p.loopDepth = 0, 0
 	expr.Block = p.parseBlockStatement()
+	p.breakDepth, p.loopDepth = breakDepth, loopDepth
 	return expr
 }
 
-func (p *Parser) parseFunctionParams() []*ast.Parameter {
+// parseFunctionParams parses the parameters up to the closing parenthesis,
+// (void) declares no parameters and a trailing ... makes the function
+// variadic.
+func (p *Parser) parseFunctionParams() ([]*ast.Parameter, bool) {
 	var params []*ast.Parameter
 	if p.curTokenIs(token.RPAREN) {
-		return params
+		return params, false
 	}
-	params = append(params, p.parseFunctionParam())
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
+	if p.curTokenIs(token.VOID) && p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
+		return params, false
+	}
+	for {
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeekToken(token.RPAREN) {
+				return nil, false
+			}
+			return params, true
+		}
 		params = append(params, p.parseFunctionParam())
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+		p.nextToken()
 	}
 	if !p.expectPeekToken(token.RPAREN) {
-		return nil
+		return nil, false
 	}
-	return params
+	return params, false
 }
 
 func (p *Parser) parseFunctionParam() *ast.Parameter {
 	if !token.IsDatatype(p.curToken.TokenType) {
-		p.errors = append(p.errors, fmt.Errorf("not valid datatype token for function parameter,got %s", p.curToken.TokenType))
+		p.errorf(p.curToken.Pos, "not valid datatype token for function parameter,got %s", p.curToken.TokenType)
 		return nil
 	}
+	tkn, typeName := p.parseTypeSpecifier()
 	param := &ast.Parameter{
-		Token: p.curToken,
-		Type:  p.curToken.TokenType,
+		Token:    tkn,
+		Type:     tkn.TokenType,
+		TypeName: typeName,
+	}
+	param.Pointers = p.parsePointers()
+	// the name may be left out in a prototype
+	if p.peekTokenIs(token.IDENTIFIER) {
+		p.nextToken()
+		ident := p.parseIdentifierExpression()
+		param.Identifier = ident.(*ast.IdentifierExpression)
+	}
+	if !p.peekTokenIs(token.LBRACK) {
+		return param
 	}
+	// an array parameter is adjusted to a pointer to the first element, so
+	// the function works on the caller's array rather than a copy
 	p.nextToken()
-	ident := p.parseIdentifierExpression()
-	param.Identifier = ident.(*ast.IdentifierExpression)
+	param.Array, param.Length = true, -1
+	if p.peekTokenIs(token.INT_LITERAL) {
+		p.nextToken()
+		length, ok := p.parseIntegerLiteral().(*ast.IntegerLiteral)
+		if !ok {
+			return nil
+		}
+		param.Length = int(length.Value)
+	}
+	if !p.expectPeekToken(token.RBRACK) {
+		return nil
+	}
+	if p.peekTokenIs(token.LBRACK) {
+		p.errorf(p.peekToken.Pos, "multi-dimensional array parameters are not supported, pass a pointer to the first element instead")
+		return nil
+	}
+	param.Pointers++
 	return param
 }
 
@@ -252,60 +596,80 @@ func (p *Parser) parseArrayDeclaration(tkn token.Token, arrIdentifier *ast.Ident
 
 	p.nextToken()
 	if p.curTokenIs(token.INT_LITERAL) {
-		len, _ := strconv.ParseInt(p.curToken.Lexeme, 10, 32)
-		expr.Length = int(len)
+		length, ok := p.parseIntegerLiteral().(*ast.IntegerLiteral)
+		if !ok {
+			return nil
+		}
+		expr.Length = int(length.Value)
 		p.expectPeekToken(token.RBRACK)
 	} else if !p.curTokenIs(token.RBRACK) {
-		p.errors = append(p.errors, fmt.Errorf("invalid token as array length found"))
+		p.errorf(p.curToken.Pos, "invalid token as array length found")
 		return nil
 	}
+	// only the outermost length may be left for the initializer to give
+	for p.peekTokenIs(token.LBRACK) {
+		p.nextToken()
+		if !p.peekTokenIs(token.INT_LITERAL) {
+			p.errorf(p.peekToken.Pos, "array %s needs the length of every dimension but the first", arrIdentifier)
+			return nil
+		}
+		p.nextToken()
+		length, ok := p.parseIntegerLiteral().(*ast.IntegerLiteral)
+		if !ok {
+			return nil
+		}
+		expr.Dims = append(expr.Dims, int(length.Value))
+		if !p.expectPeekToken(token.RBRACK) {
+			return nil
+		}
+	}
 
-	if p.peekTokenIs(token.SEMCOL) {
+	if !p.peekTokenIs(token.ASSIGN) {
 		if expr.Length == -1 {
-			p.errors = append(p.errors, fmt.Errorf("array declaration without specifying length or assigning array literal"))
+			p.errorf(p.curToken.Pos, "array declaration without specifying length or assigning array literal")
 			return nil
 		}
-		p.nextToken()
 		return expr
 	}
 	p.expectPeekToken(token.ASSIGN)
 	p.expectPeekToken(token.LBRACE)
 	p.nextToken()
 
+	// elements left out of the initializer are zeroed
 	vals := p.parseArrayLiteral()
-	if expr.Length != -1 && expr.Length != len(vals) {
-		p.errors = append(p.errors, fmt.Errorf("length of the array declared, mismatch. Declared %d, assigned %d", expr.Length, len(vals)))
+	if expr.Length != -1 && expr.Length < len(vals) {
+		p.errorf(p.curToken.Pos, "too many initializers for array %s of length %d, got %d", arrIdentifier, expr.Length, len(vals))
 		return nil
 	}
-	expr.Length = len(vals)
+	if expr.Length == -1 {
+		expr.Length = len(vals)
+	}
 	expr.Literal = vals
-	p.expectPeekToken(token.SEMCOL)
 	return expr
 }
 
 func (p *Parser) parseArrayLiteral() []ast.Expression {
 	var vals []ast.Expression
-	if p.curTokenIs(token.RBRACK) {
+	if p.curTokenIs(token.RBRACE) {
 		return vals
 	}
-	vals = append(vals, p.parseExpression(LOWEST))
+	vals = append(vals, p.parseExpression(COMMA))
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		vals = append(vals, p.parseExpression(LOWEST))
+		vals = append(vals, p.parseExpression(COMMA))
 	}
 	if !p.expectPeekToken(token.RBRACE) {
-		p.errors = append(p.errors, fmt.Errorf("missing closing bracket ] for array declaration"))
+		p.errorf(p.curToken.Pos, "missing closing bracket ] for array declaration")
 		return nil
 	}
 	return vals
 }
 
-func (p *Parser) parseArrayExpression(arrIdentifier ast.Expression) ast.Expression {
-	ident, _ := arrIdentifier.(*ast.IdentifierExpression)
+func (p *Parser) parseArrayExpression(left ast.Expression) ast.Expression {
 	expr := &ast.ArrayExpression{
-		Token:     p.curToken,
-		Identifer: ident,
+		Token: p.curToken,
+		Left:  left,
 	}
 	p.nextToken()
 	indexExp := p.parseExpression(LOWEST)
@@ -313,3 +677,24 @@ func (p *Parser) parseArrayExpression(arrIdentifier ast.Expression) ast.Expressi
 	p.expectPeekToken(token.RBRACK)
 	return expr
 }
+
+func (p *Parser) parseInitializerList() ast.Expression {
+	list := &ast.InitializerList{
+		Token: p.curToken,
+	}
+	p.nextToken()
+	list.Values = p.parseArrayLiteral()
+	return list
+}
+
+func (p *Parser) parseMemberExpression(exp ast.Expression) ast.Expression {
+	expr := &ast.MemberExpression{
+		Token: p.curToken,
+		Exp:   exp,
+	}
+	if !p.expectPeekToken(token.IDENTIFIER) {
+		return nil
+	}
+	expr.Member = p.parseIdentifierExpression().(*ast.IdentifierExpression)
+	return expr
+}
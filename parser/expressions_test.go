@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/mohamedirfanam/cynterpreter/lexer/token"
@@ -43,6 +44,133 @@ func TestIntegerLiterals(t *testing.T) {
 	}
 }
 
+func TestNumericConstants(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"0xFF", 255},
+		{"0Xff", 255},
+		{"017", 15},
+		{"0", 0},
+		{"0b1010", 10},
+		{"10u", 10},
+		{"10UL", 10},
+		{"10lu", 10},
+		{"10ll", 10},
+		{"0x10ULL", 16},
+		{"9223372036854775807", 9223372036854775807},
+		{"0xFFFFFFFFFFFFFFFF", -1},
+		{"18446744073709551615u", -1},
+		{"1e-9", 1e-9},
+		{".5", 0.5},
+		{"5.", 5.0},
+		{"1.5e+3", 1500.0},
+		{"2E2", 200.0},
+		{"0x1.8p3", 12.0},
+		{"2.5L", 2.5},
+		{"0.1f", float64(float32(0.1))},
+	}
+
+	for i, tt := range tests {
+		p := New(tt.input + ";")
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		exp := program.Statements[0].(*ast.ExpressionStatement).Expression
+		switch val := tt.expected.(type) {
+		case int:
+			lit, ok := exp.(*ast.IntegerLiteral)
+			if !ok {
+				t.Fatalf("[%d] - Expected *ast.IntegerLiteral, got %T", i, exp)
+			}
+			if lit.Value != int64(val) {
+				t.Errorf("[%d] - %s: expected %d, got %d", i, tt.input, val, lit.Value)
+			}
+		case float64:
+			lit, ok := exp.(*ast.FloatLiteral)
+			if !ok {
+				t.Fatalf("[%d] - Expected *ast.FloatLiteral, got %T", i, exp)
+			}
+			if lit.Value != val {
+				t.Errorf("[%d] - %s: expected %v, got %v", i, tt.input, val, lit.Value)
+			}
+		}
+	}
+
+	typeTests := []struct {
+		input    string
+		unsigned bool
+		long     bool
+	}{
+		{"2147483647", false, false},
+		{"2147483648", false, true},
+		{"0x7FFFFFFF", false, false},
+		{"0xFFFFFFFF", true, false},
+		{"4294967295", false, true},
+		{"0x100000000", false, true},
+		{"10u", true, false},
+		{"4294967296u", true, true},
+		{"10l", false, true},
+		{"10LL", false, true},
+		{"10ul", true, true},
+		{"0xFFFFFFFFFFFFFFFF", true, true},
+		{"9223372036854775807", false, true},
+	}
+	for i, tt := range typeTests {
+		p := New(tt.input + ";")
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		lit, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("[%d] - Expected *ast.IntegerLiteral for %s", i, tt.input)
+		}
+		if lit.Unsigned != tt.unsigned || lit.Long != tt.long {
+			t.Errorf("[%d] - %s: expected unsigned %t and long %t, got %t and %t", i, tt.input, tt.unsigned, tt.long, lit.Unsigned, lit.Long)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"08", "invalid digit '8' in octal constant 08"},
+		{"0b102", "invalid digit '2' in binary constant 0b102"},
+		{"0x", "hex constant 0x has no digits"},
+		{"0xfg", "invalid suffix \"g\" on integer constant 0xfg"},
+		{"12f", "invalid suffix \"f\" on integer constant 12f"},
+		{"10lul", "invalid suffix \"lul\" on integer constant 10lul"},
+		{"10lL", "invalid suffix \"lL\" on integer constant 10lL"},
+		{"9223372036854775808", "integer constant 9223372036854775808 is too large for its type"},
+		{"0x10000000000000000", "integer constant 0x10000000000000000 is too large for its type"},
+		{"1.5u", "invalid suffix \"u\" on floating constant 1.5u"},
+		{"1.5ff", "invalid suffix \"ff\" on floating constant 1.5ff"},
+		{"1e", "invalid floating constant 1e"},
+		{"1..2", "invalid floating constant 1..2"},
+		{"0x1.8", "hex floating constant 0x1.8 requires an exponent"},
+		{"1e999", "floating constant 1e999 is out of range"},
+		{"1e39f", "floating constant 1e39f is out of range"},
+		{"int a[0x];", "hex constant 0x has no digits"},
+	}
+	for i, tt := range errorTests {
+		input := tt.input
+		if !strings.HasSuffix(input, ";") {
+			input += ";"
+		}
+		p := New(input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("[%d] - Expected parser errors for %s", i, tt.input)
+		}
+		if !strings.Contains(p.Errors()[0].Error(), tt.expected) {
+			t.Errorf("[%d] - Expected error containing %q, got %q", i, tt.expected, p.Errors()[0])
+		}
+	}
+}
+
 func TestIdentifierExpressions(t *testing.T) {
 	input := `
 	abc;
@@ -90,6 +218,17 @@ func TestCharLiterals(t *testing.T) {
 	'\t';
 	'\r';
 	'\\';
+	'\0';
+	'\101';
+	'\x41';
+	'\x7f';
+	'\'';
+	'\"';
+	'"';
+	'\?';
+	'\a';
+	'\v';
+	'\377';
 	`
 	tests := []byte{
 		'a',
@@ -101,6 +240,17 @@ func TestCharLiterals(t *testing.T) {
 		'\t',
 		'\r',
 		'\\',
+		0,
+		'A',
+		'A',
+		0x7f,
+		'\'',
+		'"',
+		'"',
+		'?',
+		'\a',
+		'\v',
+		0xff,
 	}
 
 	p := New(input)
@@ -178,6 +328,14 @@ func TestStringLiterals(t *testing.T) {
 	"tab\tseparated";
 	"quote\"inside";
 	"path\\to\\file";
+	"%d\n";
+	"nul\0byte";
+	"\101\102C";
+	"\x41\x4a!";
+	"\1234";
+	"it's";
+	"\'single\'";
+	"/* not a comment */";
 	`
 	tests := []string{
 		"hello",
@@ -188,6 +346,14 @@ func TestStringLiterals(t *testing.T) {
 		"tab\tseparated",
 		"quote\"inside",
 		"path\\to\\file",
+		"%d\n",
+		"nul\x00byte",
+		"ABC",
+		"AJ!",
+		"S4",
+		"it's",
+		"'single'",
+		"/* not a comment */",
 	}
 
 	p := New(input)
@@ -217,6 +383,33 @@ func TestStringLiterals(t *testing.T) {
 	}
 }
 
+func TestEscapeSequenceErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"bad \q escape";`, "1:6: parser Error: unknown escape sequence \\q"},
+		{`'\z';`, "1:2: parser Error: unknown escape sequence \\z"},
+		{`"\x";`, "\\x used with no following hex digits"},
+		{`"\xg1";`, "\\x used with no following hex digits"},
+		{`"\x100";`, "hex escape sequence \\x100 out of range"},
+		{`'\777';`, "octal escape sequence \\777 out of range"},
+		{`'ab';`, "char literal 'ab' must hold exactly one character"},
+		{`'\n\n';`, "must hold exactly one character"},
+	}
+
+	for i, tt := range tests {
+		p := New(tt.input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("[%d] - Expected parser errors for %s", i, tt.input)
+		}
+		if !strings.Contains(p.Errors()[0].Error(), tt.expected) {
+			t.Errorf("[%d] - Expected error containing %q, got %q", i, tt.expected, p.Errors()[0])
+		}
+	}
+}
+
 func TestBoolLiterals(t *testing.T) {
 	input := `
 	true;
@@ -589,6 +782,24 @@ func TestExpressions(t *testing.T) {
 	(a > b) && (c <= d);
 	x + y > z && result;
 	a == b || c > d && e < f;
+	a | b ^ c & d;
+	1 << 2 + 3;
+	a & b == c;
+	x >> 1 < y << 2;
+	~a & b;
+	a && b | c;
+	i++;
+	--i;
+	-x++;
+	*p++;
+	x++ + ++y;
+	arr[i]--;
+	s.x++;
+	a ? b : c;
+	a ? b : c ? d : e;
+	a ? b ? c : d : e;
+	x > 0 || y ? x + 1 : -x;
+	max(a, b) ? a[0] : *p;
 	`
 	expected := []string{
 		"(a + b)",
@@ -646,6 +857,24 @@ func TestExpressions(t *testing.T) {
 		"((a > b) && (c <= d))",
 		"(((x + y) > z) && result)",
 		"((a == b) || ((c > d) && (e < f)))",
+		"(a | (b ^ (c & d)))",
+		"(1 << (2 + 3))",
+		"(a & (b == c))",
+		"((x >> 1) < (y << 2))",
+		"((~a) & b)",
+		"(a && (b | c))",
+		"(i++)",
+		"(--i)",
+		"(-(x++))",
+		"(*(p++))",
+		"((x++) + (++y))",
+		"(arr[i]--)",
+		"(s.x++)",
+		"(a ? b : c)",
+		"(a ? b : (c ? d : e))",
+		"(a ? (b ? c : d) : e)",
+		"(((x > 0) || y) ? (x + 1) : (-x))",
+		"(max(a, b) ? a[0] : (*p))",
 	}
 
 	p := New(input)
@@ -676,6 +905,21 @@ func TestExpressions(t *testing.T) {
 	}
 }
 
+func TestConditionalExpressionErrors(t *testing.T) {
+	tests := []string{
+		"a ? b;",
+		"a ? b c;",
+		"a ? : c;",
+	}
+	for i, input := range tests {
+		p := New(input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("[%d] - Expected parser errors for %q", i, input)
+		}
+	}
+}
+
 func TestArrayExpressions(t *testing.T) {
 	input := `
 	arr[0];
@@ -731,11 +975,97 @@ func testArrayExpression(t *testing.T, expression ast.Expression, expectedIdenti
 		t.Errorf("Token type is not LBRACK")
 	}
 
-	if expr.Identifer.Value != expectedIdentifier {
-		t.Errorf("Identifier value not correct, Expected - %s, Got - %s", expectedIdentifier, expr.Identifer.Value)
+	if expr.Left.String() != expectedIdentifier {
+		t.Errorf("Identifier value not correct, Expected - %s, Got - %s", expectedIdentifier, expr.Left)
 	}
 
 	if expr.Index.String() != fmt.Sprint(expectedIndex) {
 		t.Errorf("Index value not correct, Expected - %d, Got - %d", expectedIndex, expr.Index)
 	}
 }
+
+func TestIndexedExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		left     string
+		expected string
+	}{
+		{"getArr()[0];", "*ast.CallExpression", "getArr()[0]"},
+		{`"abc"[1];`, "*ast.StringLiteral", `"abc"[1]`},
+		{"(s)[i];", "*ast.IdentifierExpression", "s[i]"},
+		{"s.arr[i + 1];", "*ast.MemberExpression", "s.arr[(i + 1)]"},
+		{"p->m[1][2];", "*ast.ArrayExpression", "p->m[1][2]"},
+		{"(p + 1)[2];", "*ast.InfixExpression", "(p + 1)[2]"},
+		{"rows[1].arr[0];", "*ast.MemberExpression", "rows[1].arr[0]"},
+	}
+
+	for i, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		stmnt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("[%d] - Statement is not ast.ExpressionStatement, got %T", i, program.Statements[0])
+		}
+		expr, ok := stmnt.Expression.(*ast.ArrayExpression)
+		if !ok {
+			t.Fatalf("[%d] - Expression is not ast.ArrayExpression, got %T", i, stmnt.Expression)
+		}
+		if left := fmt.Sprintf("%T", expr.Left); left != tt.left {
+			t.Errorf("[%d] - Wrong subscripted expression, expected %s, got %s", i, tt.left, left)
+		}
+		if expr.String() != tt.expected {
+			t.Errorf("[%d] - Wrong expression, expected %s, got %s", i, tt.expected, expr.String())
+		}
+	}
+}
+
+func TestPointerExpressions(t *testing.T) {
+	input := `
+	*p;
+	&x;
+	**pp;
+	*p + 1;
+	*(p + 1);
+	a * *p;
+	&arr[2];
+	-*p;
+	`
+	expected := []string{
+		"(*p)",
+		"(&x)",
+		"(*(*pp))",
+		"((*p) + 1)",
+		"(*(p + 1))",
+		"(a * (*p))",
+		"(&arr[2])",
+		"(-(*p))",
+	}
+
+	p := New(input)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			t.Errorf("Parser Error: %s\n", err.Error())
+		}
+		t.Fatal("Exiting now!")
+	}
+
+	if len(program.Statements) != len(expected) {
+		t.Fatalf("Expected %d statement, got %d", len(expected), len(program.Statements))
+	}
+
+	for i, statement := range program.Statements {
+		stmt, ok := statement.(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("Statement is not of type ast.ExpressionStatement, got %T", statement)
+		}
+
+		if stmt.Expression.String() != expected[i] {
+			t.Errorf("Expression mismatch at index %d, expected %s, got %s", i, expected[i], stmt.Expression.String())
+		}
+	}
+}
@@ -18,6 +18,11 @@ type Parser struct {
 	infixParseFuncs  map[token.TokenType]infixParseFunc
 
 	errors []error
+
+	// number of enclosing statements a break can leave, and of enclosing
+	// loops a continue can jump to
+	breakDepth int
+	loopDepth  int
 }
 
 type (
@@ -26,8 +31,18 @@ type (
 )
 
 func New(input string) *Parser {
+	return newParser(lexer.New(input))
+}
+
+// NewFile returns a parser whose errors and nodes are positioned in the
+// named source file.
+func NewFile(file string, input string) *Parser {
+	return newParser(lexer.NewFile(file, input))
+}
+
+func newParser(l *lexer.Lexer) *Parser {
 	p := Parser{
-		l: lexer.New(input),
+		l: l,
 	}
 
 	p.nextToken()
@@ -46,6 +61,12 @@ func New(input string) *Parser {
 	p.registerPrefixFunc(token.PLUS, p.parsePrefixExpression)
 	p.registerPrefixFunc(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefixFunc(token.NOT, p.parsePrefixExpression)
+	p.registerPrefixFunc(token.AMP, p.parsePrefixExpression)
+	p.registerPrefixFunc(token.TILDE, p.parsePrefixExpression)
+	p.registerPrefixFunc(token.INCR, p.parsePrefixExpression)
+	p.registerPrefixFunc(token.DECR, p.parsePrefixExpression)
+	p.registerPrefixFunc(token.ASTER, p.parseDereferenceExpression)
+	p.registerPrefixFunc(token.LBRACE, p.parseInitializerList)
 
 	p.registerInfixFunc(token.PLUS, p.parseInfixExpression)
 	p.registerInfixFunc(token.MINUS, p.parseInfixExpression)
@@ -60,8 +81,22 @@ func New(input string) *Parser {
 	p.registerInfixFunc(token.GE, p.parseInfixExpression)
 	p.registerInfixFunc(token.LPAREN, p.parseCallExpression)
 	p.registerInfixFunc(token.LBRACK, p.parseArrayExpression)
+	p.registerInfixFunc(token.DOT, p.parseMemberExpression)
+	p.registerInfixFunc(token.ARROW, p.parseMemberExpression)
 	p.registerInfixFunc(token.AND, p.parseInfixExpression)
 	p.registerInfixFunc(token.OR, p.parseInfixExpression)
+	p.registerInfixFunc(token.AMP, p.parseInfixExpression)
+	p.registerInfixFunc(token.PIPE, p.parseInfixExpression)
+	p.registerInfixFunc(token.XOR, p.parseInfixExpression)
+	p.registerInfixFunc(token.LSHIFT, p.parseInfixExpression)
+	p.registerInfixFunc(token.RSHIFT, p.parseInfixExpression)
+	p.registerInfixFunc(token.INCR, p.parsePostfixExpression)
+	p.registerInfixFunc(token.DECR, p.parsePostfixExpression)
+	p.registerInfixFunc(token.QUESTION, p.parseConditionalExpression)
+	p.registerInfixFunc(token.COMMA, p.parseInfixExpression)
+	for _, op := range token.AssignmentOps {
+		p.registerInfixFunc(op, p.parseAssignmentExpression)
+	}
 
 	return &p
 }
@@ -84,7 +119,7 @@ func (p *Parser) expectPeekToken(t token.TokenType) bool {
 		p.nextToken()
 		return true
 	}
-	p.errors = append(p.errors, fmt.Errorf("Parser Error, Exptected Token - %s, Got - %s", t, p.peekToken.TokenType))
+	p.errorf(p.peekToken.Pos, "Parser Error, Exptected Token - %s, Got - %s", t, p.peekToken.TokenType)
 	return false
 }
 
@@ -112,8 +147,14 @@ func (p *Parser) registerInfixFunc(t token.TokenType, fn infixParseFunc) {
 	p.infixParseFuncs[t] = fn
 }
 
+// errorf records a parser error located at pos.
+func (p *Parser) errorf(pos token.Position, format string, a ...any) {
+	p.errors = append(p.errors, fmt.Errorf("%s: "+format, append([]any{pos}, a...)...))
+}
+
+// Errors returns the lexer errors followed by the parser errors.
 func (p *Parser) Errors() []error {
-	return p.errors
+	return append(append([]error{}, p.l.Errors()...), p.errors...)
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
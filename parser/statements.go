@@ -1,8 +1,6 @@
 package parser
 
 import (
-	"fmt"
-
 	"github.com/mohamedirfanam/cynterpreter/lexer/token"
 	"github.com/mohamedirfanam/cynterpreter/parser/ast"
 )
@@ -11,37 +9,58 @@ func (p *Parser) ParseStatement() ast.Statement {
 	switch p.curToken.TokenType {
 	case token.INT, token.CHAR, token.FLOAT, token.VOID, token.BOOL, token.STRING:
 		return p.parseDeclarationStatement()
+	case token.STRUCT, token.UNION:
+		return p.parseStructStatement()
 	case token.IF:
 		return p.parseIfStatement()
-	case token.IDENTIFIER:
-		return p.parseIdentifierStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
 	case token.WHILE:
 		return p.parseWhileStatement()
+	case token.DO:
+		return p.parseDoWhileStatement()
 	case token.FOR:
 		return p.parseForStatement()
+	case token.SWITCH:
+		return p.parseSwitchStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.LBRACE:
+		return p.parseBlockStatement()
 	default:
-		return p.parseExpressionStatement(p.curToken, nil, true)
+		return p.parseExpressionStatement()
 	}
 }
 
-func (p *Parser) parseIdentifierStatement() ast.Statement {
-	tkn := p.curToken
-	ident := p.parseExpression(LOWEST)
-	if token.IsAssignmentOp(p.peekToken.TokenType) {
-		return p.parseAssignmentStatement(tkn, ident, true, false)
-	} else {
-		return p.parseExpressionStatement(tkn, ident, false)
-	}
+func (p *Parser) parseExpressionStatement() ast.Statement {
+	stmnt := p.parseSimpleStatement()
+	p.expectPeekToken(token.SEMCOL)
+	return stmnt
 }
 
-func (p *Parser) parseExpressionStatement(tkn token.Token, exp ast.Expression, parse bool) *ast.ExpressionStatement {
-	if parse {
-		tkn = p.curToken
-		exp = p.parseExpression(LOWEST)
+// parseSimpleStatement parses an expression used as a statement, an
+// assignment at the top of it makes an assignment statement.
+func (p *Parser) parseSimpleStatement() ast.Statement {
+	tkn := p.curToken
+	exp := p.parseExpression(LOWEST)
+	switch assignment := exp.(type) {
+	case *ast.AssignmentExpression:
+		return &ast.AssignmentStatement{
+			Token:      tkn,
+			Identifier: assignment.Target,
+			Literal:    assignment.Value,
+			Op:         assignment.Token,
+		}
+	case *ast.CompoundAssignmentExpression:
+		return &ast.AssignmentStatement{
+			Token:      tkn,
+			Identifier: assignment.Target,
+			Literal:    assignment.Value,
+			Op:         assignment.Token,
+		}
 	}
-	p.expectPeekToken(token.SEMCOL)
 	return &ast.ExpressionStatement{
 		Token:      tkn,
 		Expression: exp,
@@ -49,68 +68,133 @@ func (p *Parser) parseExpressionStatement(tkn token.Token, exp ast.Expression, p
 }
 
 func (p *Parser) parseDeclarationStatement() *ast.DeclarationStatement {
+	tkn, typeName := p.parseTypeSpecifier()
+	return p.parseDeclarators(tkn, typeName)
+}
+
+// parseTypeSpecifier reads the type a declaration starts with, for struct
+// and union types it also consumes the tag following the keyword.
+func (p *Parser) parseTypeSpecifier() (token.Token, string) {
 	tkn := p.curToken
-	p.nextToken()
-	ident := p.parseIdentifierExpression()
-	var stmnt = &ast.DeclarationStatement{
-		Token:      tkn,
-		Type:       tkn.TokenType,
-		Identifier: ident.(*ast.IdentifierExpression),
+	if !p.curTokenIs(token.STRUCT) && !p.curTokenIs(token.UNION) {
+		return tkn, ""
 	}
-	p.nextToken()
-	if p.curTokenIs(token.SEMCOL) {
-		return stmnt
-	} else if p.curTokenIs(token.LPAREN) {
-		stmnt.Literal = p.parseFunctionLiteral(stmnt.Identifier)
-	} else if p.curTokenIs(token.LBRACK) {
-		stmnt.Literal = p.parseArrayDeclaration(tkn, stmnt.Identifier)
-	} else {
-		if p.curToken.TokenType != token.ASSIGN {
-			p.errors = append(p.errors, fmt.Errorf("expected '=' Sign for assigment in declaration, Got - %s", p.curToken.TokenType))
+	if !p.expectPeekToken(token.IDENTIFIER) {
+		return tkn, ""
+	}
+	return tkn, p.curToken.Lexeme
+}
+
+// parseDeclarators parses the comma separated declarators following the
+// type of a declaration up to the closing semicolon. A function definition
+// has to be the only declarator of its declaration, prototypes are declared
+// like variables.
+func (p *Parser) parseDeclarators(tkn token.Token, typeName string) *ast.DeclarationStatement {
+	stmnt := &ast.DeclarationStatement{
+		Token:    tkn,
+		Type:     tkn.TokenType,
+		TypeName: typeName,
+	}
+	for {
+		declarator := p.parseDeclarator(tkn)
+		if declarator == nil {
+			return stmnt
+		}
+		stmnt.Declarators = append(stmnt.Declarators, declarator)
+		if fl, ok := declarator.Literal.(*ast.FunctionLiteral); ok && fl.Block != nil {
+			if len(stmnt.Declarators) > 1 {
+				p.errorf(declarator.Identifier.Pos(), "function definition %s must be the only declarator of its declaration", declarator.Identifier)
+			}
+			return stmnt
+		}
+		if !p.peekTokenIs(token.COMMA) {
+			break
 		}
 		p.nextToken()
-		stmnt.Literal = p.parseExpression(LOWEST)
-		p.expectPeekToken(token.SEMCOL)
-
 	}
+	p.expectPeekToken(token.SEMCOL)
 	return stmnt
 }
 
-func (p *Parser) parseAssignmentStatement(tkn token.Token, ident ast.Expression, semCol bool, parse bool) *ast.AssignmentStatement {
-	if parse {
-		tkn = p.curToken
-		ident = p.parseExpression(LOWEST)
+func (p *Parser) parseDeclarator(tkn token.Token) *ast.Declarator {
+	pointers := p.parsePointers()
+	if !p.expectPeekToken(token.IDENTIFIER) {
+		return nil
 	}
-	var stmnt = &ast.AssignmentStatement{
-		Token:      tkn,
-		Identifier: ident.(ast.IdentifierNode),
+	declarator := &ast.Declarator{
+		Pointers:   pointers,
+		Identifier: p.parseIdentifierExpression().(*ast.IdentifierExpression),
 	}
-	p.nextToken()
-	if p.curTokenIs(token.SEMCOL) {
-		return stmnt
-	} else {
-		if !token.IsAssignmentOp(p.curToken.TokenType) {
-			p.errors = append(p.errors, fmt.Errorf("expected assignment op for assigment statement , Got - %s", p.curToken.TokenType))
-		}
-		opTkn := p.curToken
+	switch p.peekToken.TokenType {
+	case token.LPAREN:
 		p.nextToken()
-		exp := p.parseExpression(LOWEST)
-		if opTkn.TokenType != token.ASSIGN {
-			stmnt.Literal = getOpInfixExpression(ident, exp, opTkn.TokenType)
-		} else {
-			stmnt.Literal = exp
+		declarator.Literal = p.parseFunctionLiteral(declarator.Identifier)
+	case token.LBRACK:
+		p.nextToken()
+		declarator.Literal = p.parseArrayDeclaration(tkn, declarator.Identifier)
+	case token.ASSIGN:
+		p.nextToken()
+		p.nextToken()
+		declarator.Literal = p.parseExpression(COMMA)
+	case token.COMMA, token.SEMCOL:
+	default:
+		p.errorf(p.peekToken.Pos, "expected '=' Sign for assigment in declaration, Got - %s", p.peekToken.TokenType)
+		return nil
+	}
+	return declarator
+}
+
+// parseStructStatement parses either the declaration of a struct or union
+// type, or the declaration of a variable of such a type.
+func (p *Parser) parseStructStatement() ast.Statement {
+	tkn, typeName := p.parseTypeSpecifier()
+	if !p.peekTokenIs(token.LBRACE) {
+		return p.parseDeclarators(tkn, typeName)
+	}
+	stmnt := &ast.StructDeclaration{
+		Token: tkn,
+		Name:  p.parseIdentifierExpression().(*ast.IdentifierExpression),
+	}
+	p.nextToken()
+	p.nextToken()
+	for !p.curTokenIs(token.RBRACE) {
+		if !token.IsDatatype(p.curToken.TokenType) {
+			p.errorf(p.curToken.Pos, "expected member declaration in %s %s, got %s", tkn.Lexeme, typeName, p.curToken.TokenType)
+			return nil
 		}
-		if semCol {
-			p.expectPeekToken(token.SEMCOL)
+		member := p.parseDeclarationStatement()
+		for _, d := range member.Declarators {
+			if arr, ok := d.Literal.(*ast.ArrayDeclaration); d.Literal != nil && (!ok || arr.Literal != nil) {
+				p.errorf(d.Identifier.Pos(), "member %s of %s %s cannot have an initializer", d.Identifier, tkn.Lexeme, typeName)
+				return nil
+			}
 		}
+		stmnt.Members = append(stmnt.Members, member)
+		p.nextToken()
 	}
+	p.expectPeekToken(token.SEMCOL)
 	return stmnt
 }
 
+// parsePointers consumes the '*'s following a type and returns how many
+// levels of indirection they declare.
+func (p *Parser) parsePointers() int {
+	pointers := 0
+	for p.peekTokenIs(token.ASTER) {
+		p.nextToken()
+		pointers++
+	}
+	return pointers
+}
+
 func (p *Parser) parseBlockStatement() *ast.Block {
+	blk := &ast.Block{Token: p.curToken}
 	p.nextToken()
-	blk := &ast.Block{}
 	for !p.curTokenIs(token.RBRACE) {
+		if p.curTokenIs(token.EOF) {
+			p.errorf(p.curToken.Pos, "missing closing brace } for block")
+			return blk
+		}
 		statement := p.ParseStatement()
 		blk.Statements = append(blk.Statements, statement)
 		p.nextToken()
@@ -136,14 +220,16 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 	stmnt := &ast.IfStatement{
 		Token: p.curToken,
 	}
-	p.expectPeekToken(token.LPAREN)
-	stmnt.Condition = p.parseExpression(LOWEST)
-	p.expectPeekToken(token.LBRACE)
-	stmnt.Block = p.parseBlockStatement()
+	stmnt.Condition = p.parseCondition()
+	stmnt.Block = p.parseBody()
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
-		p.nextToken()
-		stmnt.ElseBlock = p.parseBlockStatement()
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			stmnt.ElseIf = p.parseIfStatement()
+		} else {
+			stmnt.ElseBlock = p.parseBody()
+		}
 	}
 	return stmnt
 }
@@ -152,10 +238,30 @@ func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 	stmnt := &ast.WhileStatement{
 		Token: p.curToken,
 	}
+	stmnt.Condition = p.parseCondition()
+	stmnt.Block = p.parseLoopBody()
+	return stmnt
+}
+
+// parseCondition parses the condition in parentheses of a control-flow
+// statement. It stops at the closing parenthesis, so that a body starting
+// with an operator such as * or ( is not read as part of the condition.
+func (p *Parser) parseCondition() ast.Expression {
 	p.expectPeekToken(token.LPAREN)
-	stmnt.Condition = p.parseExpression(LOWEST)
-	p.expectPeekToken(token.LBRACE)
-	stmnt.Block = p.parseBlockStatement()
+	p.nextToken()
+	condition := p.parseExpression(LOWEST)
+	p.expectPeekToken(token.RPAREN)
+	return condition
+}
+
+func (p *Parser) parseDoWhileStatement() *ast.DoWhileStatement {
+	stmnt := &ast.DoWhileStatement{
+		Token: p.curToken,
+	}
+	stmnt.Block = p.parseLoopBody()
+	p.expectPeekToken(token.WHILE)
+	stmnt.Condition = p.parseCondition()
+	p.expectPeekToken(token.SEMCOL)
 	return stmnt
 }
 
@@ -165,22 +271,169 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 	}
 	p.expectPeekToken(token.LPAREN)
 	p.nextToken()
-	if p.curTokenIs(token.IDENTIFIER) {
-		stmnt.InitializationStatement = p.parseAssignmentStatement(p.curToken, nil, true, true)
-	} else if token.IsDatatype(p.curToken.TokenType) {
-		stmnt.InitializationStatement = p.parseDeclarationStatement()
-	} else if p.curTokenIs(token.SEMCOL) {
-	} else {
-		p.errors = append(p.errors, fmt.Errorf("not valid statement in initializationStatement of for loop, got %s", p.curToken.TokenType))
-		return nil
+	if token.IsDatatype(p.curToken.TokenType) {
+		stmnt.Init = p.parseDeclarationStatement()
+	} else if !p.curTokenIs(token.SEMCOL) {
+		stmnt.Init = p.parseSimpleStatement()
+		p.expectPeekToken(token.SEMCOL)
 	}
 	p.nextToken()
-	stmnt.Condition = p.parseExpression(LOWEST)
-	p.expectPeekToken(token.SEMCOL)
+	if !p.curTokenIs(token.SEMCOL) {
+		stmnt.Condition = p.parseExpression(LOWEST)
+		p.expectPeekToken(token.SEMCOL)
+	}
 	p.nextToken()
-	stmnt.Increment = p.parseAssignmentStatement(p.curToken, nil, false, true)
-	p.expectPeekToken(token.RPAREN)
-	p.expectPeekToken(token.LBRACE)
-	stmnt.Block = p.parseBlockStatement()
+	if !p.curTokenIs(token.RPAREN) {
+		stmnt.Increment = p.parseSimpleStatement()
+		p.expectPeekToken(token.RPAREN)
+	}
+	stmnt.Block = p.parseLoopBody()
+	return stmnt
+}
+
+// parseLoopBody parses the body of a loop, where break and continue are
+// allowed.
+func (p *Parser) parseLoopBody() *ast.Block {
+	p.breakDepth++
+	p.loopDepth++
+	defer func() {
+		p.breakDepth--
+		p.loopDepth--
+	}()
+	return p.parseBody()
+}
+
+// parseBody parses the body of a control-flow statement, either a block in
+// braces or a single statement, which is wrapped in an implicit block.
+func (p *Parser) parseBody() *ast.Block {
+	p.nextToken()
+	if p.curTokenIs(token.LBRACE) {
+		return p.parseBlockStatement()
+	}
+	blk := &ast.Block{Implicit: true}
+	if p.curTokenIs(token.SEMCOL) {
+		return blk
+	}
+	if token.IsDatatype(p.curToken.TokenType) {
+		p.errorf(p.curToken.Pos, "declaration is not allowed as the body of a statement, use a block")
+	}
+	blk.Statements = append(blk.Statements, p.ParseStatement())
+	return blk
+}
+
+func (p *Parser) parseSwitchStatement() *ast.SwitchStatement {
+	stmnt := &ast.SwitchStatement{
+		Token: p.curToken,
+	}
+	stmnt.Condition = p.parseCondition()
+	if !p.expectPeekToken(token.LBRACE) {
+		return stmnt
+	}
+	p.nextToken()
+
+	p.breakDepth++
+	defer func() { p.breakDepth-- }()
+	labels := make(map[int64]bool)
+	var clause *ast.CaseClause
+	for !p.curTokenIs(token.RBRACE) {
+		switch p.curToken.TokenType {
+		case token.CASE, token.DEFAULT:
+			clause = p.parseCaseClause(stmnt, labels)
+			stmnt.Cases = append(stmnt.Cases, clause)
+		case token.EOF:
+			p.errorf(p.curToken.Pos, "missing closing brace } for switch statement")
+			return stmnt
+		default:
+			if clause == nil {
+				p.errorf(p.curToken.Pos, "statement in switch before any case label, got %s", p.curToken.TokenType)
+				return stmnt
+			}
+			clause.Statements = append(clause.Statements, p.ParseStatement())
+		}
+		p.nextToken()
+	}
+	return stmnt
+}
+
+// parseCaseClause parses a case or default label, rejecting labels that
+// were already used in the same switch. An invalid label is reported and the
+// clause is still parsed, so that the statements after it are.
+func (p *Parser) parseCaseClause(stmnt *ast.SwitchStatement, labels map[int64]bool) *ast.CaseClause {
+	clause := &ast.CaseClause{
+		Token: p.curToken,
+	}
+	if p.curTokenIs(token.DEFAULT) {
+		for _, other := range stmnt.Cases {
+			if other.IsDefault() {
+				p.errorf(p.curToken.Pos, "multiple default labels in one switch")
+				break
+			}
+		}
+	} else {
+		p.nextToken()
+		errs := len(p.errors)
+		clause.Value = p.parseExpression(LOWEST)
+		// a label that does not parse was reported by the expression parser
+		if clause.Value != nil && len(p.errors) == errs {
+			p.checkCaseLabel(clause.Value, labels)
+		}
+	}
+	// parsing stops at the colon when the label is missing
+	if !p.curTokenIs(token.COLON) {
+		p.expectPeekToken(token.COLON)
+	}
+	return clause
+}
+
+// checkCaseLabel reports a case label that is not a constant or that is
+// already used in the switch.
+func (p *Parser) checkCaseLabel(label ast.Expression, labels map[int64]bool) {
+	val, ok := caseLabelValue(label)
+	if !ok {
+		p.errorf(label.Pos(), "case label %s is not an integer or char constant", label)
+		return
+	}
+	if labels[val] {
+		p.errorf(label.Pos(), "duplicate case value %s in switch", label)
+		return
+	}
+	labels[val] = true
+}
+
+func caseLabelValue(exp ast.Expression) (int64, bool) {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return exp.Value, true
+	case *ast.CharLiteral:
+		return int64(exp.Value), true
+	case *ast.PrefixExpression:
+		val, ok := caseLabelValue(exp.Exp)
+		if exp.Op == "-" {
+			return -val, ok
+		}
+		return val, ok && exp.Op == "+"
+	}
+	return 0, false
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmnt := &ast.BreakStatement{
+		Token: p.curToken,
+	}
+	if p.breakDepth == 0 {
+		p.errorf(p.curToken.Pos, "break statement not within a loop or switch")
+	}
+	p.expectPeekToken(token.SEMCOL)
+	return stmnt
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmnt := &ast.ContinueStatement{
+		Token: p.curToken,
+	}
+	if p.loopDepth == 0 {
+		p.errorf(p.curToken.Pos, "continue statement not within a loop")
+	}
+	p.expectPeekToken(token.SEMCOL)
 	return stmnt
 }
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/mohamedirfanam/cynterpreter/lexer/token"
@@ -196,12 +198,94 @@ func TestDeclarationStatements(t *testing.T) {
 			t.Errorf("[%d] - Declaration type not valid, expected %s, got %s", i, expected[i].tokenType, stmnt.Type)
 		}
 
-		if stmnt.Identifier.String() != expected[i].identifier {
-			t.Errorf("[%d] - Declaration Identifier name not correct, expected %s, got %s", i, expected[i].identifier, stmnt.Identifier.String())
+		declarator := stmnt.Declarators[0]
+		if declarator.Identifier.String() != expected[i].identifier {
+			t.Errorf("[%d] - Declaration Identifier name not correct, expected %s, got %s", i, expected[i].identifier, declarator.Identifier.String())
 		}
 
-		if stmnt.Literal != nil && stmnt.Literal.String() != expected[i].literal {
-			t.Errorf("[%d] - Declaration Literal not correct, expected %s, got %s", i, expected[i].literal, stmnt.Literal.String())
+		if declarator.Literal != nil && declarator.Literal.String() != expected[i].literal {
+			t.Errorf("[%d] - Declaration Literal not correct, expected %s, got %s", i, expected[i].literal, declarator.Literal.String())
+		}
+	}
+}
+
+func TestMultipleDeclarators(t *testing.T) {
+	tests := []struct {
+		input       string
+		identifiers []string
+		pointers    []int
+		literals    []string
+	}{
+		{"int a, b = 2, c;", []string{"a", "b", "c"}, []int{0, 0, 0}, []string{"", "2", ""}},
+		{"int i = 0, j = 10;", []string{"i", "j"}, []int{0, 0}, []string{"0", "10"}},
+		{"int *p = &a, **pp, x = 1 + 2;", []string{"p", "pp", "x"}, []int{1, 2, 0}, []string{"(&a)", "", "(1 + 2)"}},
+		{"float f = g(1, 2), h;", []string{"f", "h"}, []int{0, 0}, []string{"g(1, 2)", ""}},
+		{"int x = (1, 2), y;", []string{"x", "y"}, []int{0, 0}, []string{"(1 , 2)", ""}},
+		{"struct Point p = {1, 2}, *q = &p;", []string{"p", "q"}, []int{0, 1}, []string{"{1, 2}", "(&p)"}},
+	}
+
+	for i, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		stmnt, ok := program.Statements[0].(*ast.DeclarationStatement)
+		if !ok {
+			t.Fatalf("[%d] - Not valid statement, expected ast.DeclarationStatement got %T", i, program.Statements[0])
+		}
+		if len(stmnt.Declarators) != len(tt.identifiers) {
+			t.Fatalf("[%d] - Expected %d declarators, got %d", i, len(tt.identifiers), len(stmnt.Declarators))
+		}
+		for j, d := range stmnt.Declarators {
+			if d.Identifier.Value != tt.identifiers[j] {
+				t.Errorf("[%d] - Declarator %d name not correct, expected %s, got %s", i, j, tt.identifiers[j], d.Identifier.Value)
+			}
+			if d.Pointers != tt.pointers[j] {
+				t.Errorf("[%d] - Declarator %d pointer depth not correct, expected %d, got %d", i, j, tt.pointers[j], d.Pointers)
+			}
+			literal := ""
+			if d.Literal != nil {
+				literal = d.Literal.String()
+			}
+			if literal != tt.literals[j] {
+				t.Errorf("[%d] - Declarator %d literal not correct, expected %s, got %s", i, j, tt.literals[j], literal)
+			}
+		}
+	}
+
+	p := New("int a, arr[3], b[] = {1, 2}, *c;")
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	declarators := program.Statements[0].(*ast.DeclarationStatement).Declarators
+	if len(declarators) != 4 {
+		t.Fatalf("Expected 4 declarators, got %d", len(declarators))
+	}
+	for j, length := range map[int]int{1: 3, 2: 2} {
+		arr, ok := declarators[j].Literal.(*ast.ArrayDeclaration)
+		if !ok {
+			t.Fatalf("Declarator %d is not an array, got %T", j, declarators[j].Literal)
+		}
+		if arr.Length != length {
+			t.Errorf("Declarator %d length not correct, expected %d, got %d", j, length, arr.Length)
+		}
+	}
+
+	errorTests := []string{
+		"int a,;",
+		"int a b;",
+		"int , a;",
+		"int a = 1 b = 2;",
+		"int x, f() { return 1; }",
+		"int arr[], b;",
+	}
+	for i, input := range errorTests {
+		p := New(input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("[%d] - Expected parser errors for %q", i, input)
 		}
 	}
 }
@@ -235,32 +319,33 @@ func TestAssignmentStatements(t *testing.T) {
 	`
 	expected := []struct {
 		identifier string
+		op         string
 		literal    string
 	}{
-		{"x", "10"},
-		{"l", "'a'"},
-		{"pi", "3.14"},
-		{"flag", "true"},
-		{"name", "\"hello\""},
-		{"zero", "0"},
-		{"newline", "'\\n'"},
-		{"negative", "(-2.5)"},
-		{"falseBool", "false"},
-		{"sum", "(10 + 5)"},
-		{"diff", "(a - b)"},
-		{"product", "(3.14 * 2)"},
-		{"complex", "((x + y) * z)"},
-		{"comparison", "(a > b)"},
-		{"funcCall", "add(5, 10)"},
-		{"nested", "calculate((x + y), z)"},
-		{"x", "(x + 5)"},
-		{"count", "(count - 10)"},
-		{"value", "(value * 2)"},
-		{"total", "(total / 4)"},
-		{"score", "(score + func(a, b))"},
-		{"result", "(result - (x + y))"},
-		{"product", "(product * calculate(z))"},
-		{"average", "(average / (count + 1))"},
+		{"x", "=", "10"},
+		{"l", "=", "'a'"},
+		{"pi", "=", "3.14"},
+		{"flag", "=", "true"},
+		{"name", "=", "\"hello\""},
+		{"zero", "=", "0"},
+		{"newline", "=", "'\\n'"},
+		{"negative", "=", "(-2.5)"},
+		{"falseBool", "=", "false"},
+		{"sum", "=", "(10 + 5)"},
+		{"diff", "=", "(a - b)"},
+		{"product", "=", "(3.14 * 2)"},
+		{"complex", "=", "((x + y) * z)"},
+		{"comparison", "=", "(a > b)"},
+		{"funcCall", "=", "add(5, 10)"},
+		{"nested", "=", "calculate((x + y), z)"},
+		{"x", "+=", "5"},
+		{"count", "-=", "10"},
+		{"value", "*=", "2"},
+		{"total", "/=", "4"},
+		{"score", "+=", "func(a, b)"},
+		{"result", "-=", "(x + y)"},
+		{"product", "*=", "calculate(z)"},
+		{"average", "/=", "(count + 1)"},
 	}
 
 	p := New(input)
@@ -287,6 +372,10 @@ func TestAssignmentStatements(t *testing.T) {
 			t.Errorf("[%d] - Assignment Identifier name not correct, expected %s, got %s", i, expected[i].identifier, stmnt.Identifier.String())
 		}
 
+		if stmnt.Op.Lexeme != expected[i].op {
+			t.Errorf("[%d] - Assignment operator not correct, expected %s, got %s", i, expected[i].op, stmnt.Op.Lexeme)
+		}
+
 		if stmnt.Literal != nil && stmnt.Literal.String() != expected[i].literal {
 			t.Errorf("[%d] - Assignment Value not correct, expected %s, got %s", i, expected[i].literal, stmnt.Literal.String())
 		}
@@ -326,13 +415,14 @@ func TestFunctionDeclarationStatemenet(t *testing.T) {
 		t.Errorf("Function return type not correct, expected %s, got %s", token.INT, stmnt.Type)
 	}
 
-	if stmnt.Identifier.String() != "testFunc" {
-		t.Errorf("Function name not correct, expected %s, got %s", "testFunc", stmnt.Identifier.String())
+	declarator := stmnt.Declarators[0]
+	if declarator.Identifier.String() != "testFunc" {
+		t.Errorf("Function name not correct, expected %s, got %s", "testFunc", declarator.Identifier.String())
 	}
 
-	funcLiteral, ok := stmnt.Literal.(*ast.FunctionLiteral)
+	funcLiteral, ok := declarator.Literal.(*ast.FunctionLiteral)
 	if !ok {
-		t.Fatalf("Literal is not of type ast.FunctionLiteral, got %T", stmnt.Literal)
+		t.Fatalf("Literal is not of type ast.FunctionLiteral, got %T", declarator.Literal)
 	}
 
 	expectedParams := []struct {
@@ -374,6 +464,226 @@ func TestFunctionDeclarationStatemenet(t *testing.T) {
 		}
 	}
 }
+func TestFunctionPrototypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+		variadic bool
+	}{
+		{"int helper(int);", []string{"helper(int)"}, false},
+		{"int helper(int a, char *b);", []string{"helper(int a,char *b)"}, false},
+		{"float *scale(float *, struct Point, int);", []string{"scale(float *,struct Point,int)"}, false},
+		{"void run(void);", []string{"run()"}, false},
+		{"int none();", []string{"none()"}, false},
+		{"void printf(char *format, ...);", []string{"printf(char *format,...)"}, true},
+		{"void print(...);", []string{"print(...)"}, true},
+		{"int a, twice(int), b = 2;", []string{"", "twice(int)", "2"}, false},
+	}
+
+	for i, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		if len(program.Statements) != 1 {
+			t.Fatalf("[%d] - Expected 1 statement, got %d", i, len(program.Statements))
+		}
+		stmnt, ok := program.Statements[0].(*ast.DeclarationStatement)
+		if !ok {
+			t.Fatalf("[%d] - Statement is not of type ast.DeclarationStatement, got %T", i, program.Statements[0])
+		}
+		if len(stmnt.Declarators) != len(tt.expected) {
+			t.Fatalf("[%d] - Expected %d declarators, got %d", i, len(tt.expected), len(stmnt.Declarators))
+		}
+		for j, declarator := range stmnt.Declarators {
+			if declarator.Literal == nil {
+				if tt.expected[j] != "" {
+					t.Errorf("[%d] - Declarator %d has no literal, expected %s", i, j, tt.expected[j])
+				}
+				continue
+			}
+			if declarator.Literal.String() != tt.expected[j] {
+				t.Errorf("[%d] - Declarator %d, expected %s, got %s", i, j, tt.expected[j], declarator.Literal.String())
+			}
+			fl, ok := declarator.Literal.(*ast.FunctionLiteral)
+			if !ok {
+				continue
+			}
+			if fl.Block != nil {
+				t.Errorf("[%d] - Expected a prototype without a body", i)
+			}
+			if fl.Variadic != tt.variadic {
+				t.Errorf("[%d] - Expected variadic %t, got %t", i, tt.variadic, fl.Variadic)
+			}
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int f(int) { return 1; }", "1:7: parameter name omitted in the definition of function f"},
+		{"int f(int a, ...) { return a; }", "1:6: variadic function f can only be declared, defining one is not supported"},
+		{"int f(..., int a);", "1:10: Parser Error, Exptected Token - RPAREN, Got - COMMA"},
+		{"int f(int a)", "Exptected Token - LBRACE, Got - EOF"},
+	}
+
+	for i, tt := range errorTests {
+		p := New(tt.input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("[%d] - Expected parser errors for %q", i, tt.input)
+		}
+		if !strings.Contains(p.Errors()[0].Error(), tt.expected) {
+			t.Errorf("[%d] - Expected error containing %q, got %q", i, tt.expected, p.Errors()[0])
+		}
+	}
+}
+
+func TestArrayParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		pointers int
+		length   int
+	}{
+		{"int sum(int arr[], int n){ return n; }", "int arr[]", 1, -1},
+		{"int sum(int arr[10], int n){ return n; }", "int arr[10]", 1, 10},
+		{"int first(char *words[]){ return 0; }", "char *words[]", 2, -1},
+		{"int area(struct Point pts[3]){ return 0; }", "struct Point pts[3]", 1, 3},
+		{"int sum(int [], int);", "int *", 1, -1},
+	}
+
+	for i, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		stmnt := program.Statements[0].(*ast.DeclarationStatement)
+		fl, ok := stmnt.Declarators[0].Literal.(*ast.FunctionLiteral)
+		if !ok {
+			t.Fatalf("[%d] - Literal is not of type ast.FunctionLiteral, got %T", i, stmnt.Declarators[0].Literal)
+		}
+		param := fl.Params[0]
+		if param.String() != tt.expected {
+			t.Errorf("[%d] - Wrong parameter, expected %s, got %s", i, tt.expected, param.String())
+		}
+		if !param.Array || param.Pointers != tt.pointers || param.Length != tt.length {
+			t.Errorf("[%d] - Wrong array parameter, expected %d pointers and length %d, got array %t, %d pointers and length %d", i, tt.pointers, tt.length, param.Array, param.Pointers, param.Length)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int f(int arr[) { return 0; }", "1:15: Parser Error, Exptected Token - RBRACK, Got - RPAREN"},
+		{"int f(int arr[n]) { return 0; }", "Exptected Token - RBRACK, Got - IDENTIFIER"},
+	}
+
+	for i, tt := range errorTests {
+		p := New(tt.input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("[%d] - Expected parser errors for %q", i, tt.input)
+		}
+		if !strings.Contains(p.Errors()[0].Error(), tt.expected) {
+			t.Errorf("[%d] - Expected error containing %q, got %q", i, tt.expected, p.Errors()[0])
+		}
+	}
+}
+
+func TestMultiDimensionalArrays(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		length   int
+		dims     []int
+	}{
+		{"int m[2][3];", "m[2][3] =  {}", 2, []int{3}},
+		{"int m[2][3] = {{1, 2, 3}, {4}};", "m[2][3] =  {{1, 2, 3},{4}}", 2, []int{3}},
+		{"int m[2][3] = {{1, 2, 3}};", "m[2][3] =  {{1, 2, 3}}", 2, []int{3}},
+		{"int m[][2] = {{1, 2}, {3, 4}, {5, 6}};", "m[3][2] =  {{1, 2},{3, 4},{5, 6}}", 3, []int{2}},
+		{"char c[2][3][4];", "c[2][3][4] =  {}", 2, []int{3, 4}},
+	}
+
+	for i, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		stmnt := program.Statements[0].(*ast.DeclarationStatement)
+		arr, ok := stmnt.Declarators[0].Literal.(*ast.ArrayDeclaration)
+		if !ok {
+			t.Fatalf("[%d] - Literal is not of type ast.ArrayDeclaration, got %T", i, stmnt.Declarators[0].Literal)
+		}
+		if arr.String() != tt.expected {
+			t.Errorf("[%d] - Wrong declaration, expected %s, got %s", i, tt.expected, arr.String())
+		}
+		if arr.Length != tt.length || !slices.Equal(arr.Dims, tt.dims) {
+			t.Errorf("[%d] - Wrong dimensions, expected %d %v, got %d %v", i, tt.length, tt.dims, arr.Length, arr.Dims)
+		}
+	}
+
+	subscripts := []struct {
+		input    string
+		expected string
+		depth    int
+	}{
+		{"m[1][2];", "m[1][2]", 2},
+		{"c[i][j + 1][0];", "c[i][(j + 1)][0]", 3},
+		{"m[1];", "m[1]", 1},
+	}
+
+	for i, tt := range subscripts {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		exp, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ArrayExpression)
+		if !ok {
+			t.Fatalf("[%d] - Expression is not of type ast.ArrayExpression, got %T", i, program.Statements[0])
+		}
+		if exp.String() != tt.expected {
+			t.Errorf("[%d] - Wrong subscript, expected %s, got %s", i, tt.expected, exp.String())
+		}
+		depth := 1
+		left := exp.Left
+		for inner, ok := left.(*ast.ArrayExpression); ok; inner, ok = left.(*ast.ArrayExpression) {
+			depth++
+			left = inner.Left
+		}
+		if depth != tt.depth || left.String() != tt.expected[:1] {
+			t.Errorf("[%d] - Wrong subscript nesting, expected %d levels of %s, got %d levels of %s", i, tt.depth, tt.expected[:1], depth, left)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int m[2][];", "1:10: array m needs the length of every dimension but the first"},
+		{"int m[2][n];", "array m needs the length of every dimension but the first"},
+		{"int m[1][2] = {{1}, {2}};", "too many initializers for array m of length 1, got 2"},
+		{"int f(int m[][3]) { return 0; }", "multi-dimensional array parameters are not supported, pass a pointer to the first element instead"},
+	}
+
+	for i, tt := range errorTests {
+		p := New(tt.input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("[%d] - Expected parser errors for %q", i, tt.input)
+		}
+		if !strings.Contains(p.Errors()[0].Error(), tt.expected) {
+			t.Errorf("[%d] - Expected error containing %q, got %q", i, tt.expected, p.Errors()[0])
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	input := `
 	return 5;
@@ -590,21 +900,22 @@ func TestForStatement(t *testing.T) {
 		t.Fatalf("Statement is not of type ast.ForStatement, got %T", program.Statements[0])
 	}
 
-	initStmt, ok := stmnt.InitializationStatement.(*ast.DeclarationStatement)
+	initStmt, ok := stmnt.Init.(*ast.DeclarationStatement)
 	if !ok {
-		t.Fatalf("Initialization statement is not of type ast.DeclarationStatement, got %T", stmnt.InitializationStatement)
+		t.Fatalf("Initialization statement is not of type ast.DeclarationStatement, got %T", stmnt.Init)
 	}
 
 	if initStmt.Type != token.INT {
 		t.Errorf("Initialization statement type not correct, expected %s, got %s", token.INT, initStmt.Type)
 	}
 
-	if initStmt.Identifier.String() != "i" {
-		t.Errorf("Initialization statement identifier not correct, expected %s, got %s", "i", initStmt.Identifier.String())
+	initDeclarator := initStmt.Declarators[0]
+	if initDeclarator.Identifier.String() != "i" {
+		t.Errorf("Initialization statement identifier not correct, expected %s, got %s", "i", initDeclarator.Identifier.String())
 	}
 
-	if initStmt.Literal.String() != "0" {
-		t.Errorf("Initialization statement literal not correct, expected %s, got %s", "0", initStmt.Literal.String())
+	if initDeclarator.Literal.String() != "0" {
+		t.Errorf("Initialization statement literal not correct, expected %s, got %s", "0", initDeclarator.Literal.String())
 	}
 
 	expectedCondition := "(i < 10)"
@@ -616,12 +927,17 @@ func TestForStatement(t *testing.T) {
 		t.Fatal("Increment statement is nil")
 	}
 
-	if stmnt.Increment.Identifier.String() != "i" {
-		t.Errorf("Increment statement identifier not correct, expected %s, got %s", "i", stmnt.Increment.Identifier.String())
+	increment, ok := stmnt.Increment.(*ast.AssignmentStatement)
+	if !ok {
+		t.Fatalf("Increment statement is not of type ast.AssignmentStatement, got %T", stmnt.Increment)
+	}
+
+	if increment.Identifier.String() != "i" {
+		t.Errorf("Increment statement identifier not correct, expected %s, got %s", "i", increment.Identifier.String())
 	}
 
-	if stmnt.Increment.Literal.String() != "(i + 1)" {
-		t.Errorf("Increment statement literal not correct, expected %s, got %s", "(i + 1)", stmnt.Increment.Literal.String())
+	if increment.Literal.String() != "(i + 1)" {
+		t.Errorf("Increment statement literal not correct, expected %s, got %s", "(i + 1)", increment.Literal.String())
 	}
 
 	if stmnt.Block == nil {
@@ -648,6 +964,115 @@ func TestForStatement(t *testing.T) {
 	}
 }
 
+func TestForIncrementExpressions(t *testing.T) {
+	tests := []struct {
+		input     string
+		increment string
+	}{
+		{"for (int i = 0; i < 10; i++) { x += i; }", "(i++)"},
+		{"for (int i = 10; i > 0; --i) { x += i; }", "(--i)"},
+		{"for (i = 0; i < n; p++) { x += 1; }", "(p++)"},
+	}
+
+	for i, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		stmnt, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("[%d] - Statement is not of type ast.ForStatement, got %T", i, program.Statements[0])
+		}
+		increment, ok := stmnt.Increment.(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("[%d] - Increment is not of type ast.ExpressionStatement, got %T", i, stmnt.Increment)
+		}
+		if increment.String() != tt.increment {
+			t.Errorf("[%d] - Increment not correct, expected %s, got %s", i, tt.increment, increment.String())
+		}
+	}
+}
+
+func TestAssignmentExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		isAssign bool
+	}{
+		{"a = b = 0;", "a = (b = 0)", true},
+		{"x += y = 2;", "x += (y = 2)", true},
+		{"a = b ? c : d;", "a = (b ? c : d)", true},
+		{"*p = q->x = 1;", "(*p) = (q->x = 1)", true},
+		{"(*pp).x = 1;", "(*pp).x = 1", true},
+		{"(c = next()) != 10;", "((c = next()) != 10)", false},
+		{"a = b <<= 2;", "a = (b <<= 2)", true},
+		{"x = 1, y = 2;", "((x = 1) , (y = 2))", false},
+		{"i++, j--;", "((i++) , (j--))", false},
+		{"f(a, b = 2);", "f(a, (b = 2))", false},
+		{"a = (b, c);", "a = (b , c)", true},
+	}
+
+	for i, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		if len(program.Statements) != 1 {
+			t.Fatalf("[%d] - Expected 1 statement, got %d", i, len(program.Statements))
+		}
+		_, isAssign := program.Statements[0].(*ast.AssignmentStatement)
+		if isAssign != tt.isAssign {
+			t.Errorf("[%d] - Statement type not correct, got %T", i, program.Statements[0])
+		}
+		if program.Statements[0].String() != tt.expected {
+			t.Errorf("[%d] - Expected %s, got %s", i, tt.expected, program.Statements[0].String())
+		}
+	}
+
+	errorTests := []string{
+		"1 = 2;",
+		"a + b = 3;",
+		"c ? x : y = 1;",
+		"f() = 1;",
+	}
+	for i, input := range errorTests {
+		p := New(input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("[%d] - Expected parser errors for %q", i, input)
+		}
+	}
+}
+
+func TestForStatementClauses(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"for (;;) { break; }", "for (; ;)"},
+		{"for (i = 0, j = n; i < j; i++, j--) { x += 1; }", "for (((i = 0) , (j = n)); (i < j);((i++) , (j--)))"},
+		{"for (; i < 3;) { i++; }", "for (; (i < 3);)"},
+		{"for (f(); ; i = i + 1) { break; }", "for (f(); ;i = (i + 1))"},
+	}
+
+	for i, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		stmnt, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("[%d] - Statement is not of type ast.ForStatement, got %T", i, program.Statements[0])
+		}
+		if !strings.HasPrefix(stmnt.String(), tt.expected) {
+			t.Errorf("[%d] - Expected prefix %s, got %s", i, tt.expected, stmnt.String())
+		}
+	}
+}
+
 func TestArrayDeclaration(t *testing.T) {
 	input := `
 	int arr1[5];
@@ -698,13 +1123,14 @@ func TestArrayDeclaration(t *testing.T) {
 			t.Errorf("[%d] - Declaration type not valid, expected %s, got %s", i, expected[i].tokenType, stmt.Type)
 		}
 
-		if stmt.Identifier.String() != expected[i].identifier {
-			t.Errorf("[%d] - Declaration Identifier name not correct, expected %s, got %s", i, expected[i].identifier, stmt.Identifier.String())
+		declarator := stmt.Declarators[0]
+		if declarator.Identifier.String() != expected[i].identifier {
+			t.Errorf("[%d] - Declaration Identifier name not correct, expected %s, got %s", i, expected[i].identifier, declarator.Identifier.String())
 		}
 
-		arrayLiteral, ok := stmt.Literal.(*ast.ArrayDeclaration)
+		arrayLiteral, ok := declarator.Literal.(*ast.ArrayDeclaration)
 		if !ok {
-			t.Fatalf("[%d] - Literal is not of type ast.ArrayDeclaration, got %T", i, stmt.Literal)
+			t.Fatalf("[%d] - Literal is not of type ast.ArrayDeclaration, got %T", i, declarator.Literal)
 		}
 
 		if arrayLiteral.Length != expected[i].length {
@@ -724,3 +1150,650 @@ func TestArrayDeclaration(t *testing.T) {
 		}
 	}
 }
+
+func TestPointerStatements(t *testing.T) {
+	input := `
+	int *p = &x;
+	char **pp;
+	float *q = arr;
+	*p = 5;
+	**pp = 'a';
+	*(q + 1) += 2.5;
+	int sum(int *a, int **b){
+		return *a + **b;
+	}
+	`
+
+	p := New(input)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			t.Errorf("Parser Error: %s\n", err.Error())
+		}
+		t.Fatal("Exiting now!")
+	}
+
+	if len(program.Statements) != 7 {
+		t.Fatalf("Expected 7 statements, got %d", len(program.Statements))
+	}
+
+	declarations := []struct {
+		tokenType  token.TokenType
+		pointers   int
+		identifier string
+	}{
+		{token.INT, 1, "p"},
+		{token.CHAR, 2, "pp"},
+		{token.FLOAT, 1, "q"},
+	}
+	for i, expected := range declarations {
+		stmnt, ok := program.Statements[i].(*ast.DeclarationStatement)
+		if !ok {
+			t.Fatalf("[%d] - Not valid statement, expected ast.DeclarationStatement got %T", i, program.Statements[i])
+		}
+		if stmnt.Type != expected.tokenType {
+			t.Errorf("[%d] - Declaration type not valid, expected %s, got %s", i, expected.tokenType, stmnt.Type)
+		}
+		declarator := stmnt.Declarators[0]
+		if declarator.Pointers != expected.pointers {
+			t.Errorf("[%d] - Pointer depth not valid, expected %d, got %d", i, expected.pointers, declarator.Pointers)
+		}
+		if declarator.Identifier.String() != expected.identifier {
+			t.Errorf("[%d] - Declaration Identifier name not correct, expected %s, got %s", i, expected.identifier, declarator.Identifier.String())
+		}
+	}
+
+	assignments := []struct {
+		identifier string
+		literal    string
+	}{
+		{"(*p)", "5"},
+		{"(*(*pp))", "'a'"},
+		{"(*(q + 1))", "2.5"},
+	}
+	for i, expected := range assignments {
+		stmnt, ok := program.Statements[i+3].(*ast.AssignmentStatement)
+		if !ok {
+			t.Fatalf("[%d] - Not valid statement, expected ast.AssignmentStatement got %T", i, program.Statements[i+3])
+		}
+		if stmnt.Identifier.String() != expected.identifier {
+			t.Errorf("[%d] - Assignment Identifier not correct, expected %s, got %s", i, expected.identifier, stmnt.Identifier.String())
+		}
+		if stmnt.Literal.String() != expected.literal {
+			t.Errorf("[%d] - Assignment Value not correct, expected %s, got %s", i, expected.literal, stmnt.Literal.String())
+		}
+	}
+
+	funcLiteral := program.Statements[6].(*ast.DeclarationStatement).Declarators[0].Literal.(*ast.FunctionLiteral)
+	if funcLiteral.Params[0].Pointers != 1 || funcLiteral.Params[1].Pointers != 2 {
+		t.Errorf("Parameter pointer depth not correct, got %d and %d", funcLiteral.Params[0].Pointers, funcLiteral.Params[1].Pointers)
+	}
+}
+
+func TestStructStatements(t *testing.T) {
+	input := `
+	struct Point {
+		int x;
+		int y;
+		struct Point *next;
+		char tag[4];
+	};
+	union Data { int i; float f; };
+	struct Point p = {1, 2};
+	struct Point *pp = &p;
+	p.x = 5;
+	pp->next->y += 1;
+	struct Point move(struct Point a, union Data *d){
+		return a;
+	}
+	`
+
+	p := New(input)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			t.Errorf("Parser Error: %s\n", err.Error())
+		}
+		t.Fatal("Exiting now!")
+	}
+
+	if len(program.Statements) != 7 {
+		t.Fatalf("Expected 7 statements, got %d", len(program.Statements))
+	}
+
+	structs := []struct {
+		name    string
+		union   bool
+		members []string
+	}{
+		{"Point", false, []string{"x", "y", "next", "tag"}},
+		{"Data", true, []string{"i", "f"}},
+	}
+	for i, expected := range structs {
+		stmnt, ok := program.Statements[i].(*ast.StructDeclaration)
+		if !ok {
+			t.Fatalf("[%d] - Not valid statement, expected ast.StructDeclaration got %T", i, program.Statements[i])
+		}
+		if stmnt.Name.Value != expected.name || stmnt.IsUnion() != expected.union {
+			t.Errorf("[%d] - Struct declaration not valid, expected %s (union %t), got %s (union %t)", i, expected.name, expected.union, stmnt.Name.Value, stmnt.IsUnion())
+		}
+		if len(stmnt.Members) != len(expected.members) {
+			t.Fatalf("[%d] - Expected %d members, got %d", i, len(expected.members), len(stmnt.Members))
+		}
+		for j, member := range stmnt.Members {
+			if member.Declarators[0].Identifier.Value != expected.members[j] {
+				t.Errorf("[%d] - Member name not correct, expected %s, got %s", i, expected.members[j], member.Declarators[0].Identifier.Value)
+			}
+		}
+	}
+
+	declarations := []struct {
+		typeName string
+		pointers int
+		literal  string
+	}{
+		{"Point", 0, "{1, 2}"},
+		{"Point", 1, "(&p)"},
+	}
+	for i, expected := range declarations {
+		stmnt, ok := program.Statements[i+2].(*ast.DeclarationStatement)
+		if !ok {
+			t.Fatalf("[%d] - Not valid statement, expected ast.DeclarationStatement got %T", i, program.Statements[i+2])
+		}
+		declarator := stmnt.Declarators[0]
+		if stmnt.Type != token.STRUCT || stmnt.TypeName != expected.typeName || declarator.Pointers != expected.pointers {
+			t.Errorf("[%d] - Declaration type not valid, expected struct %s with %d pointers, got %s %s with %d", i, expected.typeName, expected.pointers, stmnt.Type, stmnt.TypeName, declarator.Pointers)
+		}
+		if declarator.Literal.String() != expected.literal {
+			t.Errorf("[%d] - Declaration value not correct, expected %s, got %s", i, expected.literal, declarator.Literal.String())
+		}
+	}
+
+	assignments := []struct {
+		identifier string
+		literal    string
+	}{
+		{"p.x", "5"},
+		{"pp->next->y", "1"},
+	}
+	for i, expected := range assignments {
+		stmnt, ok := program.Statements[i+4].(*ast.AssignmentStatement)
+		if !ok {
+			t.Fatalf("[%d] - Not valid statement, expected ast.AssignmentStatement got %T", i, program.Statements[i+4])
+		}
+		if _, ok := stmnt.Identifier.(*ast.MemberExpression); !ok {
+			t.Errorf("[%d] - Assignment Identifier not valid, expected ast.MemberExpression got %T", i, stmnt.Identifier)
+		}
+		if stmnt.Identifier.String() != expected.identifier {
+			t.Errorf("[%d] - Assignment Identifier not correct, expected %s, got %s", i, expected.identifier, stmnt.Identifier.String())
+		}
+		if stmnt.Literal.String() != expected.literal {
+			t.Errorf("[%d] - Assignment Value not correct, expected %s, got %s", i, expected.literal, stmnt.Literal.String())
+		}
+	}
+
+	funcLiteral := program.Statements[6].(*ast.DeclarationStatement).Declarators[0].Literal.(*ast.FunctionLiteral)
+	if funcLiteral.Params[0].TypeName != "Point" || funcLiteral.Params[1].Type != token.UNION || funcLiteral.Params[1].Pointers != 1 {
+		t.Errorf("Struct parameters not correct, got %s and %s", funcLiteral.Params[0], funcLiteral.Params[1])
+	}
+
+	errorTests := []string{
+		"struct P { int x = 1; };",
+		"struct P { x; };",
+		"struct P { int x; }",
+		"p.;",
+	}
+	for i, input := range errorTests {
+		p := New(input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("[%d] - Expected parser errors for %q, got none", i, input)
+		}
+	}
+}
+
+func TestSwitchStatement(t *testing.T) {
+	input := `
+	switch (x) {
+		case 1:
+		case 'b':
+			y = 2;
+			break;
+		default:
+			y = 3;
+		case -4:
+			y = 4;
+	}
+	`
+
+	p := New(input)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			t.Errorf("Parser Error: %s\n", err.Error())
+		}
+		t.Fatal("Exiting now!")
+	}
+
+	stmnt, ok := program.Statements[0].(*ast.SwitchStatement)
+	if !ok {
+		t.Fatalf("Not valid statement, expected ast.SwitchStatement got %T", program.Statements[0])
+	}
+	if stmnt.Condition.String() != "x" {
+		t.Errorf("Switch condition not correct, expected x, got %s", stmnt.Condition.String())
+	}
+
+	cases := []struct {
+		value      string
+		statements int
+	}{
+		{"1", 0},
+		{"'b'", 2},
+		{"", 1},
+		{"(-4)", 1},
+	}
+	if len(stmnt.Cases) != len(cases) {
+		t.Fatalf("Expected %d case clauses, got %d", len(cases), len(stmnt.Cases))
+	}
+	for i, expected := range cases {
+		clause := stmnt.Cases[i]
+		if expected.value == "" {
+			if !clause.IsDefault() {
+				t.Errorf("[%d] - Expected default clause, got case %s", i, clause.Value)
+			}
+		} else if clause.IsDefault() || clause.Value.String() != expected.value {
+			t.Errorf("[%d] - Case value not correct, expected %s, got %v", i, expected.value, clause.Value)
+		}
+		if len(clause.Statements) != expected.statements {
+			t.Errorf("[%d] - Expected %d statements, got %d", i, expected.statements, len(clause.Statements))
+		}
+	}
+	if _, ok := stmnt.Cases[1].Statements[1].(*ast.BreakStatement); !ok {
+		t.Errorf("Expected ast.BreakStatement, got %T", stmnt.Cases[1].Statements[1])
+	}
+
+	errorTests := []string{
+		"switch (x) { case 1: case 1: }",
+		"switch (x) { case 'A': case 65: }",
+		"switch (x) { default: default: }",
+		"switch (x) { x = 1; case 1: }",
+		"switch (x) { case y: }",
+		"switch (x) { case 1.5: }",
+		"switch (x) { case 1: ",
+		"break;",
+	}
+	for i, input := range errorTests {
+		p := New(input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("[%d] - Expected parser errors for %q, got none", i, input)
+		}
+	}
+
+	// a bad label is reported once and the rest of the switch still parses
+	recoveryTests := []struct {
+		input    string
+		expected []string
+	}{
+		{"switch (x) { case : y = 1; }", []string{"1:19: no valid prefix parsing function found for token COLON"}},
+		{"switch (x) { case -: break; }", []string{"1:20: no valid prefix parsing function found for token COLON"}},
+		{"switch (x) { case 1: break; case 1: break; }", []string{"1:34: duplicate case value 1 in switch"}},
+	}
+	for i, tt := range recoveryTests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		var errs []string
+		for _, err := range p.Errors() {
+			errs = append(errs, err.Error())
+		}
+		if !slices.Equal(errs, tt.expected) {
+			t.Errorf("[%d] - Expected errors %q, got %q", i, tt.expected, errs)
+		}
+		if stmnt, ok := program.Statements[0].(*ast.SwitchStatement); !ok || stmnt == nil || len(stmnt.Cases) == 0 {
+			t.Errorf("[%d] - Expected the switch with its clauses, got %v", i, program.Statements[0])
+		}
+	}
+}
+
+func TestBreakContinueStatements(t *testing.T) {
+	input := `
+	while (x < 10) {
+		if (x == 5) {
+			break;
+		}
+		continue;
+	}
+	for (int i = 0; i < 3; i += 1) {
+		switch (i) {
+			case 1:
+				continue;
+		}
+	}
+	`
+
+	p := New(input)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			t.Errorf("Parser Error: %s\n", err.Error())
+		}
+		t.Fatal("Exiting now!")
+	}
+
+	whileStmnt := program.Statements[0].(*ast.WhileStatement)
+	ifStmnt := whileStmnt.Block.Statements[0].(*ast.IfStatement)
+	if _, ok := ifStmnt.Block.Statements[0].(*ast.BreakStatement); !ok {
+		t.Errorf("Expected ast.BreakStatement, got %T", ifStmnt.Block.Statements[0])
+	}
+	if _, ok := whileStmnt.Block.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Errorf("Expected ast.ContinueStatement, got %T", whileStmnt.Block.Statements[1])
+	}
+	switchStmnt := program.Statements[1].(*ast.ForStatement).Block.Statements[0].(*ast.SwitchStatement)
+	if _, ok := switchStmnt.Cases[0].Statements[0].(*ast.ContinueStatement); !ok {
+		t.Errorf("Expected ast.ContinueStatement, got %T", switchStmnt.Cases[0].Statements[0])
+	}
+
+	errorTests := []string{
+		"break;",
+		"continue;",
+		"if (x) { break; }",
+		"switch (x) { case 1: continue; }",
+		"while (x) { int f() { break; } }",
+		"while (x) { break }",
+	}
+	for i, input := range errorTests {
+		p := New(input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("[%d] - Expected parser errors for %q, got none", i, input)
+		}
+	}
+}
+
+func TestDoWhileStatement(t *testing.T) {
+	input := `
+	do {
+		count = count + 1;
+		if (count == 3) {
+			continue;
+		}
+	} while (count < 10 && flag == true);
+	`
+
+	p := New(input)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			t.Errorf("Parser Error: %s\n", err.Error())
+		}
+		t.Fatal("Exiting now!")
+	}
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("Number of statements not valid, expected 1, got %d", len(program.Statements))
+	}
+
+	stmnt, ok := program.Statements[0].(*ast.DoWhileStatement)
+	if !ok {
+		t.Fatalf("Statement is not of type ast.DoWhileStatement, got %T", program.Statements[0])
+	}
+
+	expectedCondition := "((count < 10) && (flag == true))"
+	if stmnt.Condition.String() != expectedCondition {
+		t.Errorf("Do while condition not correct, expected %s, got %s", expectedCondition, stmnt.Condition.String())
+	}
+
+	if len(stmnt.Block.Statements) != 2 {
+		t.Fatalf("Number of statements in do while body not correct, expected 2, got %d", len(stmnt.Block.Statements))
+	}
+
+	errorTests := []string{
+		"do { x = 1; } while (x < 3)",
+		"do { x = 1; } (x < 3);",
+		"do { x = 1; } while x < 3;",
+	}
+	for i, input := range errorTests {
+		p := New(input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("[%d] - Expected parser errors for %q, got none", i, input)
+		}
+	}
+}
+
+func TestElseIfAndBracelessBodies(t *testing.T) {
+	input := `
+	if (x < 0) return -1;
+	else if (x == 0) {
+		return 0;
+	} else if (x < 10)
+		x = 10;
+	else
+		return 1;
+	while (x < 5) x += 1;
+	for (int i = 0; i < 3; i += 1) ;
+	if (a) if (b) x = 1; else x = 2;
+	`
+
+	p := New(input)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			t.Errorf("Parser Error: %s\n", err.Error())
+		}
+		t.Fatal("Exiting now!")
+	}
+
+	if len(program.Statements) != 4 {
+		t.Fatalf("Expected 4 statements, got %d", len(program.Statements))
+	}
+
+	ifStmnt := program.Statements[0].(*ast.IfStatement)
+	if !ifStmnt.Block.Implicit || len(ifStmnt.Block.Statements) != 1 {
+		t.Errorf("Expected an implicit block with one statement, got %+v", ifStmnt.Block)
+	}
+	if _, ok := ifStmnt.Block.Statements[0].(*ast.ReturnStatement); !ok {
+		t.Errorf("Expected ast.ReturnStatement, got %T", ifStmnt.Block.Statements[0])
+	}
+	if ifStmnt.ElseBlock != nil || ifStmnt.ElseIf == nil {
+		t.Fatalf("Expected an else if, got else block %v", ifStmnt.ElseBlock)
+	}
+	conditions := []string{"(x < 0)", "(x == 0)", "(x < 10)"}
+	implicit := []bool{true, false, true}
+	stmnt := ifStmnt
+	for i, condition := range conditions {
+		if stmnt.Condition.String() != condition {
+			t.Errorf("[%d] - If condition not correct, expected %s, got %s", i, condition, stmnt.Condition.String())
+		}
+		if stmnt.Block.Implicit != implicit[i] {
+			t.Errorf("[%d] - Expected implicit block %t, got %t", i, implicit[i], stmnt.Block.Implicit)
+		}
+		if i < len(conditions)-1 {
+			stmnt = stmnt.ElseIf
+		}
+	}
+	if stmnt.ElseIf != nil || stmnt.ElseBlock == nil || !stmnt.ElseBlock.Implicit {
+		t.Errorf("Expected the chain to end with an implicit else block, got %v", stmnt.ElseBlock)
+	}
+
+	whileStmnt := program.Statements[1].(*ast.WhileStatement)
+	if !whileStmnt.Block.Implicit || len(whileStmnt.Block.Statements) != 1 {
+		t.Errorf("Expected an implicit while body with one statement, got %+v", whileStmnt.Block)
+	}
+	forStmnt := program.Statements[2].(*ast.ForStatement)
+	if !forStmnt.Block.Implicit || len(forStmnt.Block.Statements) != 0 {
+		t.Errorf("Expected an empty implicit for body, got %+v", forStmnt.Block)
+	}
+
+	// else binds to the nearest if
+	outer := program.Statements[3].(*ast.IfStatement)
+	if outer.ElseBlock != nil {
+		t.Errorf("Expected no else block on the outer if, got %v", outer.ElseBlock)
+	}
+	inner := outer.Block.Statements[0].(*ast.IfStatement)
+	if inner.ElseBlock == nil || inner.ElseBlock.String() != "x = 2;\n" {
+		t.Errorf("Expected the else block on the inner if, got %v", inner.ElseBlock)
+	}
+
+	// a body starting with an operator does not extend the condition
+	operatorBodies := []struct {
+		input     string
+		condition string
+		body      string
+	}{
+		{"if (p) *p = 5;", "p", "(*p) = 5;\n"},
+		{"while (n) --n;", "n", "(--n);\n"},
+		{"while (x < 3) (*p)++;", "(x < 3)", "((*p)++);\n"},
+	}
+	for i, tt := range operatorBodies {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		var condition ast.Expression
+		var body *ast.Block
+		switch stmnt := program.Statements[0].(type) {
+		case *ast.IfStatement:
+			condition, body = stmnt.Condition, stmnt.Block
+		case *ast.WhileStatement:
+			condition, body = stmnt.Condition, stmnt.Block
+		}
+		if condition.String() != tt.condition || body.String() != tt.body {
+			t.Errorf("[%d] - Expected condition %s and body %q, got %s and %q", i, tt.condition, tt.body, condition, body)
+		}
+	}
+
+	errorTests := []string{
+		"if (x) int y = 1;",
+		"if (x) return 1; else",
+		"while (x)",
+		"do x++; while (x) - 1;",
+	}
+	for i, input := range errorTests {
+		p := New(input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("[%d] - Expected parser errors for %q, got none", i, input)
+		}
+	}
+}
+
+func TestBlockStatements(t *testing.T) {
+	tests := []struct {
+		input      string
+		statements int
+		nested     int
+	}{
+		{"{ int x = 1; x++; }", 2, 0},
+		{"{ }", 0, 0},
+		{"{ int x = 1; { int x = 2; } }", 2, 1},
+		{"{ { } { } }", 2, 2},
+	}
+
+	for i, tt := range tests {
+		p := New(tt.input)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("[%d] - Parser errors: %v", i, p.Errors())
+		}
+		if len(program.Statements) != 1 {
+			t.Fatalf("[%d] - Expected 1 statement, got %d", i, len(program.Statements))
+		}
+		blk, ok := program.Statements[0].(*ast.Block)
+		if !ok {
+			t.Fatalf("[%d] - Not valid statement, expected *ast.Block got %T", i, program.Statements[0])
+		}
+		if len(blk.Statements) != tt.statements {
+			t.Errorf("[%d] - Expected %d statements in block, got %d", i, tt.statements, len(blk.Statements))
+		}
+		nested := 0
+		for _, stmnt := range blk.Statements {
+			if _, ok := stmnt.(*ast.Block); ok {
+				nested++
+			}
+		}
+		if nested != tt.nested {
+			t.Errorf("[%d] - Expected %d nested blocks, got %d", i, tt.nested, nested)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// compute a sum
+int sum(int a, int b) {
+	/* the parameters
+	   are added */
+	return a + b; // done
+}`
+	p := New(input)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	if len(program.Statements) != 1 {
+		t.Fatalf("Expected 1 statement, got %d", len(program.Statements))
+	}
+
+	p = New("int a = 1; /* never closed")
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || !strings.Contains(p.Errors()[0].Error(), "unterminated block comment") {
+		t.Fatalf("Expected unterminated block comment error, got %v", p.Errors())
+	}
+}
+
+func TestPositions(t *testing.T) {
+	input := "int main() {\n\tint a = 1;\n\ta = a + 2;\n\treturn a;\n}"
+	p := NewFile("prog.c", input)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	fn := program.Statements[0].(*ast.DeclarationStatement).Declarators[0].Literal.(*ast.FunctionLiteral)
+	stmnts := fn.Block.Statements
+	if len(stmnts) != 3 {
+		t.Fatalf("Expected 3 statements, got %d", len(stmnts))
+	}
+	tests := []struct {
+		node     ast.Node
+		expected string
+	}{
+		{program, "prog.c:1:1"},
+		{fn.Block, "prog.c:1:12"},
+		{stmnts[0], "prog.c:2:2"},
+		{stmnts[1], "prog.c:3:2"},
+		{stmnts[1].(*ast.AssignmentStatement).Literal, "prog.c:3:8"},
+		{stmnts[2], "prog.c:4:2"},
+	}
+	for i, tt := range tests {
+		if tt.node.Pos().String() != tt.expected {
+			t.Errorf("[%d] - Wrong position for %s, expected %s, got %s", i, tt.node, tt.expected, tt.node.Pos())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"int main() {\n\tint a = 1\n\treturn a;\n}", "prog.c:3:2: "},
+		{"int a = 1;\nint b = ;", "prog.c:2:9: "},
+		{"int a;\n  /* open", "prog.c:2:3: "},
+		{"int f() {\n\tbreak;\n}", "prog.c:2:2: "},
+	}
+	for i, tt := range errorTests {
+		p := NewFile("prog.c", tt.input)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Fatalf("[%d] - Expected parser errors for %q", i, tt.input)
+		}
+		if !strings.HasPrefix(p.Errors()[0].Error(), tt.expected) {
+			t.Errorf("[%d] - Expected error at %s, got %v", i, tt.expected, p.Errors()[0])
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package preprocessor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mohamedirfanam/cynterpreter/lexer/token"
+	"github.com/mohamedirfanam/cynterpreter/parser"
+	"github.com/mohamedirfanam/cynterpreter/parser/ast"
+)
+
+// evalCondition evaluates the constant expression of an #if or #elif. The
+// defined operator is applied first, then the macros are expanded and the
+// identifiers left over are replaced with 0.
+func (pp *Preprocessor) evalCondition(args []ppToken, pos token.Position) bool {
+	tokens, err := pp.replaceDefined(args)
+	if err == nil {
+		tokens, err = pp.expand(tokens)
+	}
+	if err != nil {
+		pp.errorf(pos, "%s", err)
+		return false
+	}
+	for i, t := range tokens {
+		if t.kind == ppIdent && t.text != "true" && t.text != "false" {
+			tokens[i] = ppToken{kind: ppNumber, text: "0", line: t.line}
+		}
+	}
+	expr := strings.TrimSpace(joinTokens(tokens))
+	if expr == "" {
+		pp.errorf(pos, "#if with no expression")
+		return false
+	}
+
+	p := parser.New(expr + ";")
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 || len(program.Statements) != 1 {
+		pp.errorf(pos, "invalid #if expression %s", expr)
+		return false
+	}
+	stmnt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		pp.errorf(pos, "invalid #if expression %s", expr)
+		return false
+	}
+	val, err := evalConstant(stmnt.Expression)
+	if err != nil {
+		pp.errorf(pos, "%s in #if expression %s", err, expr)
+		return false
+	}
+	return val != 0
+}
+
+// replaceDefined replaces defined NAME and defined(NAME) with 1 or 0.
+func (pp *Preprocessor) replaceDefined(tokens []ppToken) ([]ppToken, error) {
+	var out []ppToken
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].kind != ppIdent || tokens[i].text != "defined" {
+			out = append(out, tokens[i])
+			continue
+		}
+		j := nextToken(tokens, i+1)
+		paren := j < len(tokens) && tokens[j].text == "("
+		if paren {
+			j = nextToken(tokens, j+1)
+		}
+		if j == len(tokens) || tokens[j].kind != ppIdent {
+			return nil, fmt.Errorf("operator \"defined\" requires an identifier")
+		}
+		val := "0"
+		if _, ok := pp.macros[tokens[j].text]; ok {
+			val = "1"
+		}
+		if paren {
+			j = nextToken(tokens, j+1)
+			if j == len(tokens) || tokens[j].text != ")" {
+				return nil, fmt.Errorf("missing ')' after \"defined\"")
+			}
+		}
+		out = append(out, ppToken{kind: ppNumber, text: val, line: tokens[i].line})
+		i = j
+	}
+	return out, nil
+}
+
+// evalConstant evaluates an integer constant expression, && || and ?: only
+// evaluate the operands they need.
+func evalConstant(exp ast.Expression) (int64, error) {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return exp.Value, nil
+	case *ast.CharLiteral:
+		return int64(exp.Value), nil
+	case *ast.BoolLiteral:
+		return boolValue(exp.Value), nil
+	case *ast.PrefixExpression:
+		val, err := evalConstant(exp.Exp)
+		if err != nil {
+			return 0, err
+		}
+		switch exp.Op {
+		case "-":
+			return -val, nil
+		case "+":
+			return val, nil
+		case "!":
+			return boolValue(val == 0), nil
+		case "~":
+			return ^val, nil
+		}
+	case *ast.ConditionalExpression:
+		cond, err := evalConstant(exp.Condition)
+		if err != nil {
+			return 0, err
+		}
+		if cond != 0 {
+			return evalConstant(exp.Consequence)
+		}
+		return evalConstant(exp.Alternative)
+	case *ast.InfixExpression:
+		return evalConstantInfix(exp)
+	}
+	return 0, fmt.Errorf("%s is not an integer constant", exp)
+}
+
+func evalConstantInfix(exp *ast.InfixExpression) (int64, error) {
+	left, err := evalConstant(exp.LeftExp)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case exp.Op == "&&" && left == 0:
+		return 0, nil
+	case exp.Op == "||" && left != 0:
+		return 1, nil
+	}
+	right, err := evalConstant(exp.RightExp)
+	if err != nil {
+		return 0, err
+	}
+	switch exp.Op {
+	case "&&", "||":
+		return boolValue(right != 0), nil
+	case ",":
+		return right, nil
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/", "%":
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		if exp.Op == "/" {
+			return left / right, nil
+		}
+		return left % right, nil
+	case "<<", ">>":
+		if right < 0 || right >= 64 {
+			return 0, fmt.Errorf("shift count %d out of range", right)
+		}
+		if exp.Op == "<<" {
+			return left << right, nil
+		}
+		return left >> right, nil
+	case "&":
+		return left & right, nil
+	case "|":
+		return left | right, nil
+	case "^":
+		return left ^ right, nil
+	case "==":
+		return boolValue(left == right), nil
+	case "!=":
+		return boolValue(left != right), nil
+	case "<":
+		return boolValue(left < right), nil
+	case "<=":
+		return boolValue(left <= right), nil
+	case ">":
+		return boolValue(left > right), nil
+	case ">=":
+		return boolValue(left >= right), nil
+	}
+	return 0, fmt.Errorf("operator %s is not allowed", exp.Op)
+}
+
+func boolValue(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,33 @@
+package preprocessor
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// the standard headers bundled with the interpreter, they define the
+// standard macros and document the built-in functions
+//
+//go:embed include/*.h
+var headers embed.FS
+
+// standardHeader returns the bundled header name, its path is written as
+// <name> so positions inside it read like the header of a system compiler.
+func standardHeader(name string) (string, string, bool) {
+	data, err := headers.ReadFile(path.Join("include", name))
+	if err != nil {
+		return "", "", false
+	}
+	return "<" + name + ">", string(data), true
+}
+
+// StandardHeaders returns the names of the bundled headers.
+func StandardHeaders() []string {
+	names, _ := fs.Glob(headers, "include/*.h")
+	for i, name := range names {
+		names[i] = strings.TrimPrefix(name, "include/")
+	}
+	return names
+}
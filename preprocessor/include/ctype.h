@@ -0,0 +1,5 @@
+/* ctype.h - character classification
+ *
+ * No classification functions are built into the interpreter yet.
+ */
+#pragma once
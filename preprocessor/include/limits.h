@@ -0,0 +1,15 @@
+/* limits.h - sizes of the integer types
+ *
+ * The limits are those of a 32 bit int and a signed char, which every
+ * int and char of the interpreter can hold.
+ */
+#pragma once
+
+#define CHAR_BIT 8
+#define CHAR_MIN (-128)
+#define CHAR_MAX 127
+#define SCHAR_MIN (-128)
+#define SCHAR_MAX 127
+#define UCHAR_MAX 255
+#define INT_MIN (-INT_MAX - 1)
+#define INT_MAX 2147483647
@@ -0,0 +1,9 @@
+/* math.h - mathematics
+ *
+ * No math functions are built into the interpreter yet.
+ */
+#pragma once
+
+#define M_E 2.71828182845904523536
+#define M_PI 3.14159265358979323846
+#define M_SQRT2 1.41421356237309504880
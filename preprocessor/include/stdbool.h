@@ -0,0 +1,7 @@
+/* stdbool.h - boolean type
+ *
+ * bool, true and false are keywords of the interpreter.
+ */
+#pragma once
+
+#define __bool_true_false_are_defined 1
@@ -0,0 +1,4 @@
+/* stddef.h - common definitions */
+#pragma once
+
+#define NULL 0
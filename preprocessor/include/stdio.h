@@ -0,0 +1,14 @@
+/* stdio.h - input and output
+ *
+ * The functions are built into the interpreter, print prints its arguments
+ * one after the other and input reads a line from stdin, printing the
+ * prompt it is given first.
+ */
+#pragma once
+
+#define NULL 0
+#define EOF (-1)
+
+void print(...);
+void printf(char *format, ...);
+string input(...);
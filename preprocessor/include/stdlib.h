@@ -0,0 +1,10 @@
+/* stdlib.h - general utilities
+ *
+ * No utility functions are built into the interpreter yet.
+ */
+#pragma once
+
+#define NULL 0
+#define EXIT_SUCCESS 0
+#define EXIT_FAILURE 1
+#define RAND_MAX 2147483647
@@ -0,0 +1,8 @@
+/* string.h - string handling
+ *
+ * No string functions are built into the interpreter yet, strings are
+ * indexed and compared directly.
+ */
+#pragma once
+
+#define NULL 0
@@ -0,0 +1,495 @@
+package preprocessor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mohamedirfanam/cynterpreter/lexer/token"
+)
+
+type ppKind int
+
+const (
+	ppIdent ppKind = iota
+	ppNumber
+	ppString // string or char literal
+	ppPunct
+	ppSpace
+	ppNewline
+	ppPlacemarker // an empty argument operand of ##, removed after substitution
+)
+
+// ppToken is a preprocessing token, the text is kept as written so the
+// expanded source can be put back together for the lexer.
+type ppToken struct {
+	kind ppKind
+	text string
+	line int
+	hide map[string]bool // macros that must not expand this token again
+}
+
+// punctuators longer than one character, longest first
+var ppPunctuators = []string{
+	"...", "<<=", ">>=",
+	"##", "->", "++", "--", "<<", ">>", "<=", ">=", "==", "!=", "&&", "||",
+	"+=", "-=", "*=", "/=", "%=", "&=", "^=", "|=",
+}
+
+// tokenize splits src into preprocessing tokens, line is the line number of
+// its first line.
+func tokenize(src string, line int) []ppToken {
+	var tokens []ppToken
+	for i := 0; i < len(src); {
+		ch := src[i]
+		start := i
+		kind := ppPunct
+		switch {
+		case ch == '\n':
+			kind = ppNewline
+			i++
+		case token.IsWhiteSpace(ch):
+			kind = ppSpace
+			for i < len(src) && src[i] != '\n' && token.IsWhiteSpace(src[i]) {
+				i++
+			}
+		case token.IsWordStartSymbol(ch):
+			kind = ppIdent
+			for i < len(src) && token.IsWordSymbol(src[i]) {
+				i++
+			}
+		case token.IsDigit(ch) || ch == '.' && i+1 < len(src) && token.IsDigit(src[i+1]):
+			kind = ppNumber
+			i = scanNumber(src, i)
+		case ch == '"' || ch == '\'':
+			kind = ppString
+			i = scanQuoted(src, i)
+		default:
+			i++
+			for _, punct := range ppPunctuators {
+				if strings.HasPrefix(src[start:], punct) {
+					i = start + len(punct)
+					break
+				}
+			}
+		}
+		tokens = append(tokens, ppToken{kind: kind, text: src[start:i], line: line})
+		if kind == ppNewline {
+			line++
+		}
+	}
+	return tokens
+}
+
+// scanNumber returns the end of the numeric literal starting at i, read the
+// same way the lexer reads it.
+func scanNumber(src string, i int) int {
+	hex := strings.HasPrefix(src[i:], "0x") || strings.HasPrefix(src[i:], "0X")
+	for i++; i < len(src); i++ {
+		prev, ch := src[i-1], src[i]
+		isExponent := !hex && (prev == 'e' || prev == 'E') || hex && (prev == 'p' || prev == 'P')
+		if !token.IsWordSymbol(ch) && ch != '.' && !(isExponent && (ch == '+' || ch == '-')) {
+			break
+		}
+	}
+	return i
+}
+
+// scanQuoted returns the end of the string or char literal starting at i, an
+// unterminated literal ends with its line.
+func scanQuoted(src string, i int) int {
+	quote := src[i]
+	for i++; i < len(src) && src[i] != '\n'; i++ {
+		if src[i] == '\\' && i+1 < len(src) && src[i+1] != '\n' {
+			i++
+		} else if src[i] == quote {
+			return i + 1
+		}
+	}
+	return i
+}
+
+func joinTokens(tokens []ppToken) string {
+	var str strings.Builder
+	for _, t := range tokens {
+		if t.kind != ppPlacemarker {
+			str.WriteString(t.text)
+		}
+	}
+	return str.String()
+}
+
+// trimSpace drops the whitespace tokens at both ends of tokens.
+func trimSpace(tokens []ppToken) []ppToken {
+	for len(tokens) > 0 && isSpace(tokens[0]) {
+		tokens = tokens[1:]
+	}
+	for len(tokens) > 0 && isSpace(tokens[len(tokens)-1]) {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+func isSpace(t ppToken) bool {
+	return t.kind == ppSpace || t.kind == ppNewline
+}
+
+// nextToken returns the index of the first token at or after i that is not
+// whitespace, or len(tokens).
+func nextToken(tokens []ppToken, i int) int {
+	for i < len(tokens) && isSpace(tokens[i]) {
+		i++
+	}
+	return i
+}
+
+type macro struct {
+	name     string
+	function bool
+	params   []string
+	variadic bool // the last parameter is ..., its arguments are __VA_ARGS__
+	body     []ppToken
+}
+
+// param returns the index of the parameter called name, or -1.
+func (m *macro) param(name string) int {
+	if !m.function {
+		return -1
+	}
+	for i, param := range m.params {
+		if param == name {
+			return i
+		}
+	}
+	if m.variadic && name == "__VA_ARGS__" {
+		return len(m.params)
+	}
+	return -1
+}
+
+// sameAs reports whether other is an identical redefinition of m, the only
+// kind of redefinition C allows.
+func (m *macro) sameAs(other *macro) bool {
+	return m.function == other.function && m.variadic == other.variadic &&
+		strings.Join(m.params, ",") == strings.Join(other.params, ",") &&
+		strings.Join(strings.Fields(joinTokens(m.body)), " ") == strings.Join(strings.Fields(joinTokens(other.body)), " ")
+}
+
+// parseMacro parses the name, parameters and replacement list of a #define.
+func parseMacro(tokens []ppToken) (*macro, error) {
+	i := nextToken(tokens, 0)
+	if i == len(tokens) {
+		return nil, fmt.Errorf("no macro name given in #define directive")
+	}
+	if tokens[i].kind != ppIdent {
+		return nil, fmt.Errorf("macro names must be identifiers, got %s", tokens[i].text)
+	}
+	m := &macro{name: tokens[i].text}
+	if m.name == "defined" {
+		return nil, fmt.Errorf("\"defined\" cannot be used as a macro name")
+	}
+	i++
+	// a function-like macro has its parenthesis right after the name
+	if i < len(tokens) && tokens[i].text == "(" {
+		m.function = true
+		params, end, err := parseParams(tokens, i+1)
+		if err != nil {
+			return nil, fmt.Errorf("in definition of macro %s: %s", m.name, err)
+		}
+		if len(params) > 0 && params[len(params)-1] == "..." {
+			m.variadic = true
+			params = params[:len(params)-1]
+		}
+		m.params = params
+		i = end
+	}
+	m.body = trimSpace(tokens[i:])
+	for j, t := range m.body {
+		if t.kind != ppPunct {
+			continue
+		}
+		if t.text == "##" && (j == 0 || j == len(m.body)-1) {
+			return nil, fmt.Errorf("'##' cannot appear at either end of macro %s", m.name)
+		}
+		if t.text == "#" && m.function {
+			k := nextToken(m.body, j+1)
+			if k == len(m.body) || m.param(m.body[k].text) < 0 {
+				return nil, fmt.Errorf("'#' is not followed by a macro parameter in macro %s", m.name)
+			}
+		}
+	}
+	return m, nil
+}
+
+// parseParams parses a macro parameter list starting after its opening
+// parenthesis and returns the names and the index after the closing one.
+func parseParams(tokens []ppToken, i int) ([]string, int, error) {
+	var params []string
+	i = nextToken(tokens, i)
+	if i < len(tokens) && tokens[i].text == ")" {
+		return params, i + 1, nil
+	}
+	for {
+		if i == len(tokens) {
+			return nil, i, fmt.Errorf("missing ')' in macro parameter list")
+		}
+		t := tokens[i]
+		if t.kind != ppIdent && t.text != "..." {
+			return nil, i, fmt.Errorf("expected parameter name, got %s", t.text)
+		}
+		for _, param := range params {
+			if param == t.text {
+				return nil, i, fmt.Errorf("duplicate macro parameter %s", t.text)
+			}
+		}
+		params = append(params, t.text)
+		i = nextToken(tokens, i+1)
+		if i == len(tokens) {
+			return nil, i, fmt.Errorf("missing ')' in macro parameter list")
+		}
+		if tokens[i].text == ")" {
+			return params, i + 1, nil
+		}
+		if tokens[i].text != "," || t.text == "..." {
+			return nil, i, fmt.Errorf("expected ',' or ')' in macro parameter list, got %s", tokens[i].text)
+		}
+		i = nextToken(tokens, i+1)
+	}
+}
+
+// expand replaces the macro invocations in tokens and rescans the result,
+// a token is not expanded again by a macro found in its hide set.
+func (pp *Preprocessor) expand(tokens []ppToken) ([]ppToken, error) {
+	var out []ppToken
+	for len(tokens) > 0 {
+		t := tokens[0]
+		tokens = tokens[1:]
+		if t.kind != ppIdent || t.hide[t.text] {
+			out = append(out, t)
+			continue
+		}
+		switch t.text {
+		case "__LINE__":
+			out = append(out, ppToken{kind: ppNumber, text: strconv.Itoa(t.line), line: t.line})
+			continue
+		case "__FILE__":
+			out = append(out, ppToken{kind: ppString, text: quote(pp.file), line: t.line})
+			continue
+		}
+		m, ok := pp.macros[t.text]
+		if !ok {
+			out = append(out, t)
+			continue
+		}
+		hide := withHidden(t.hide, m.name)
+		if !m.function {
+			body, err := pp.subst(m, nil, hide, t.line)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(body, tokens...)
+			continue
+		}
+		// a function-like macro name not followed by ( is left alone
+		if i := nextToken(tokens, 0); i == len(tokens) || tokens[i].text != "(" {
+			out = append(out, t)
+			continue
+		}
+		args, newlines, rest, err := collectArgs(m, tokens)
+		if err != nil {
+			return nil, err
+		}
+		body, err := pp.subst(m, args, hide, t.line)
+		if err != nil {
+			return nil, err
+		}
+		// newlines inside the invocation follow its expansion, so the lines
+		// after it keep their numbers
+		tokens = append(append(body, newlines...), rest...)
+	}
+	return out, nil
+}
+
+// collectArgs splits the parenthesized arguments of an invocation of m at
+// the top level commas, it returns the arguments, the newlines they spanned
+// and the tokens after the closing parenthesis.
+func collectArgs(m *macro, tokens []ppToken) ([][]ppToken, []ppToken, []ppToken, error) {
+	var args [][]ppToken
+	var arg, newlines []ppToken
+	depth := 0
+	for i := nextToken(tokens, 0) + 1; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.kind == ppNewline {
+			newlines = append(newlines, t)
+			t = ppToken{kind: ppSpace, text: " ", line: t.line}
+		}
+		switch {
+		case t.text == "(":
+			depth++
+		case t.text == ")" && depth > 0:
+			depth--
+		case t.text == ")":
+			args = append(args, trimSpace(arg))
+			args, err := checkArgs(m, args)
+			return args, newlines, tokens[i+1:], err
+		case t.text == "," && depth == 0:
+			args = append(args, trimSpace(arg))
+			arg = nil
+			continue
+		}
+		arg = append(arg, t)
+	}
+	return nil, nil, nil, fmt.Errorf("unterminated argument list invoking macro %s", m.name)
+}
+
+// checkArgs checks the number of arguments given to m, the variadic ones
+// are joined back with their commas into one argument.
+func checkArgs(m *macro, args [][]ppToken) ([][]ppToken, error) {
+	if len(m.params) == 0 && len(args) == 1 && len(args[0]) == 0 {
+		args = nil
+	}
+	if !m.variadic {
+		if len(args) != len(m.params) {
+			return nil, fmt.Errorf("macro %s requires %d arguments, but %d given", m.name, len(m.params), len(args))
+		}
+		return args, nil
+	}
+	if len(args) < len(m.params) {
+		return nil, fmt.Errorf("macro %s requires at least %d arguments, but %d given", m.name, len(m.params), len(args))
+	}
+	var variadic []ppToken
+	for i, arg := range args[len(m.params):] {
+		if i > 0 {
+			variadic = append(variadic, ppToken{kind: ppPunct, text: ","}, ppToken{kind: ppSpace, text: " "})
+		}
+		variadic = append(variadic, arg...)
+	}
+	return append(args[:len(m.params)], variadic), nil
+}
+
+// subst builds the replacement of an invocation of m, parameters become their
+// macro expanded arguments, except next to # and ## where the arguments are
+// used as written.
+func (pp *Preprocessor) subst(m *macro, args [][]ppToken, hide map[string]bool, line int) ([]ppToken, error) {
+	// the replacement is set apart so it cannot merge with its neighbours
+	out := []ppToken{{kind: ppSpace, text: " "}}
+	body := m.body
+	for i := 0; i < len(body); i++ {
+		t := body[i]
+		switch {
+		case t.kind == ppPunct && t.text == "#" && m.function:
+			j := nextToken(body, i+1)
+			out = append(out, stringify(args[m.param(body[j].text)]))
+			i = j
+		case t.kind == ppPunct && t.text == "##":
+			j := nextToken(body, i+1)
+			rhs := []ppToken{body[j]}
+			if idx := m.param(body[j].text); idx >= 0 {
+				rhs = args[idx]
+			}
+			var err error
+			if out, err = paste(trimSpace(out), rhs); err != nil {
+				return nil, err
+			}
+			i = j
+		case t.kind == ppIdent && m.param(t.text) >= 0:
+			arg := args[m.param(t.text)]
+			if j := nextToken(body, i+1); j < len(body) && body[j].text == "##" {
+				if len(arg) == 0 {
+					arg = []ppToken{{kind: ppPlacemarker}}
+				}
+				out = append(out, arg...)
+				continue
+			}
+			expanded, err := pp.expand(append([]ppToken{}, arg...))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, expanded...)
+		default:
+			out = append(out, t)
+		}
+	}
+	out = append(out, ppToken{kind: ppSpace, text: " "})
+
+	result := out[:0]
+	for _, t := range out {
+		if t.kind == ppPlacemarker {
+			continue
+		}
+		t.line = line
+		t.hide = union(t.hide, hide)
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// paste joins the last token of lhs and the first token of rhs into one.
+func paste(lhs []ppToken, rhs []ppToken) ([]ppToken, error) {
+	if len(rhs) == 0 {
+		return lhs, nil
+	}
+	last := len(lhs) - 1
+	if lhs[last].kind == ppPlacemarker {
+		return append(lhs[:last], rhs...), nil
+	}
+	if rhs[0].kind == ppPlacemarker {
+		return append(lhs, rhs[1:]...), nil
+	}
+	text := lhs[last].text + rhs[0].text
+	pasted := tokenize(text, lhs[last].line)
+	if len(pasted) != 1 {
+		return nil, fmt.Errorf("pasting %s and %s does not give a valid preprocessing token", lhs[last].text, rhs[0].text)
+	}
+	lhs[last] = pasted[0]
+	return append(lhs, rhs[1:]...), nil
+}
+
+// stringify turns an argument into a string literal, the whitespace between
+// its tokens becomes a single space.
+func stringify(arg []ppToken) ppToken {
+	var str strings.Builder
+	space := false
+	for _, t := range arg {
+		if isSpace(t) {
+			space = str.Len() > 0
+			continue
+		}
+		if space {
+			str.WriteByte(' ')
+			space = false
+		}
+		if t.kind == ppString {
+			str.WriteString(escape(t.text))
+		} else {
+			str.WriteString(t.text)
+		}
+	}
+	return ppToken{kind: ppString, text: `"` + str.String() + `"`}
+}
+
+func quote(s string) string {
+	return `"` + escape(s) + `"`
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+func withHidden(hide map[string]bool, name string) map[string]bool {
+	return union(hide, map[string]bool{name: true})
+}
+
+func union(a, b map[string]bool) map[string]bool {
+	if len(b) == 0 {
+		return a
+	}
+	hide := make(map[string]bool, len(a)+len(b))
+	for name := range a {
+		hide[name] = true
+	}
+	for name := range b {
+		hide[name] = true
+	}
+	return hide
+}
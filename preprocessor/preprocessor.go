@@ -0,0 +1,426 @@
+// Package preprocessor implements the C preprocessor that runs on a source
+// file before it is lexed: #include, #define and #undef, and conditional
+// compilation with #if, #ifdef, #ifndef, #elif, #else and #endif.
+//
+// The output keeps every line of the input on its line, directives and
+// skipped groups become empty lines, and the text of an included file is
+// wrapped in #line markers, so positions reported by the lexer and parser
+// still point into the original files.
+package preprocessor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mohamedirfanam/cynterpreter/lexer/token"
+)
+
+// maximum nesting of #include, deeper nesting is most likely a recursive
+// include without a guard
+const maxIncludeDepth = 200
+
+type Preprocessor struct {
+	includePaths []string
+	macros       map[string]*macro
+	once         map[string]bool // files marked with #pragma once
+
+	file   string // file being processed
+	depth  int    // nesting of #include
+	errors []error
+}
+
+// New returns a preprocessor searching includePaths for the files of
+// #include directives not found next to the including file.
+func New(includePaths ...string) *Preprocessor {
+	return &Preprocessor{
+		includePaths: includePaths,
+		macros:       make(map[string]*macro),
+		once:         make(map[string]bool),
+	}
+}
+
+func (pp *Preprocessor) Errors() []error {
+	return pp.errors
+}
+
+// Define defines an object-like macro, as if by #define name body.
+func (pp *Preprocessor) Define(name string, body string) {
+	m, err := parseMacro(tokenize(name+" "+body, 0))
+	if err != nil {
+		pp.errors = append(pp.errors, err)
+		return
+	}
+	pp.macros[m.name] = m
+}
+
+// Process preprocesses the source of file, macros defined by it stay defined
+// for the sources processed after it.
+func (pp *Preprocessor) Process(file string, src string) string {
+	var out strings.Builder
+	pp.process(file, src, &out)
+	return out.String()
+}
+
+// condition is an #if, #ifdef or #ifndef group being processed
+type condition struct {
+	pos         token.Position
+	outerActive bool // the enclosing group is kept
+	active      bool // the current branch is kept
+	taken       bool // a branch of the conditional was kept
+	sawElse     bool
+}
+
+// fileState is the state of the file being processed
+type fileState struct {
+	file       string
+	dir        string
+	conditions []*condition
+	out        *strings.Builder
+}
+
+func (st *fileState) active() bool {
+	return len(st.conditions) == 0 || st.conditions[len(st.conditions)-1].active
+}
+
+func (pp *Preprocessor) process(file string, src string, out *strings.Builder) {
+	outerFile := pp.file
+	pp.file = file
+	defer func() { pp.file = outerFile }()
+
+	st := &fileState{file: file, dir: filepath.Dir(file), out: out}
+	lines := strings.Split(pp.clean(file, src), "\n")
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// consecutive text lines are expanded together, so the arguments of a
+	// macro invocation can span lines
+	var text []string
+	textLine := 1
+	flush := func() {
+		if len(text) > 0 {
+			pp.expandText(st, strings.Join(text, "\n"), textLine)
+			text = nil
+		}
+	}
+	for i, line := range lines {
+		lineNo := i + 1
+		trimmed := strings.TrimLeft(line, " \t\r\f\v")
+		if strings.HasPrefix(trimmed, "#") {
+			flush()
+			pos := token.Position{File: file, Line: lineNo, Column: len(line) - len(trimmed) + 1}
+			pp.directive(st, trimmed[1:], pos)
+			continue
+		}
+		if !st.active() {
+			out.WriteString("\n")
+			continue
+		}
+		if len(text) == 0 {
+			textLine = lineNo
+		}
+		text = append(text, line)
+	}
+	flush()
+	for _, cond := range st.conditions {
+		pp.errorf(cond.pos, "unterminated conditional directive")
+	}
+}
+
+// expandText expands the macros of a run of text lines starting at line.
+func (pp *Preprocessor) expandText(st *fileState, text string, line int) {
+	tokens, err := pp.expand(tokenize(text, line))
+	if err != nil {
+		pp.errorf(token.Position{File: st.file, Line: line, Column: 1}, "%s", err)
+		// the text is kept unexpanded so the lines stay where they are
+		st.out.WriteString(text + "\n")
+		return
+	}
+	st.out.WriteString(joinTokens(tokens) + "\n")
+}
+
+// directive runs the directive on a line starting with #, rest is the line
+// after the #. Only the conditional directives run in a skipped group.
+func (pp *Preprocessor) directive(st *fileState, rest string, pos token.Position) {
+	tokens := tokenize(rest, pos.Line)
+	i := nextToken(tokens, 0)
+	if i == len(tokens) {
+		// the null directive
+		st.out.WriteString("\n")
+		return
+	}
+	name := tokens[i].text
+	args := tokens[i+1:]
+
+	switch name {
+	case "if", "ifdef", "ifndef":
+		pp.ifDirective(st, name, args, pos)
+	case "elif":
+		pp.elifDirective(st, args, pos)
+	case "else", "endif":
+		if len(st.conditions) == 0 {
+			pp.errorf(pos, "#%s without #if", name)
+			break
+		}
+		cond := st.conditions[len(st.conditions)-1]
+		if name == "endif" {
+			st.conditions = st.conditions[:len(st.conditions)-1]
+			break
+		}
+		if cond.sawElse {
+			pp.errorf(pos, "#else after #else")
+		}
+		cond.sawElse = true
+		cond.active = cond.outerActive && !cond.taken
+		cond.taken = true
+	default:
+		if !st.active() {
+			break
+		}
+		switch name {
+		case "include":
+			pp.include(st, args, pos)
+			return
+		case "define":
+			pp.define(args, pos)
+		case "undef":
+			pp.undef(args, pos)
+		case "error":
+			pp.errorf(pos, "#error %s", strings.TrimSpace(joinTokens(args)))
+		case "pragma":
+			if strings.TrimSpace(joinTokens(args)) == "once" {
+				pp.once[st.file] = true
+			}
+		case "line":
+			// line markers are left for the lexer
+			st.out.WriteString("#" + rest + "\n")
+			return
+		default:
+			pp.errorf(pos, "invalid preprocessing directive #%s", name)
+		}
+	}
+	st.out.WriteString("\n")
+}
+
+func (pp *Preprocessor) ifDirective(st *fileState, name string, args []ppToken, pos token.Position) {
+	cond := &condition{pos: pos, outerActive: st.active()}
+	st.conditions = append(st.conditions, cond)
+	if !cond.outerActive {
+		return
+	}
+	switch name {
+	case "if":
+		cond.active = pp.evalCondition(args, pos)
+	default:
+		macroName, ok := pp.macroName(name, args, pos)
+		if !ok {
+			return
+		}
+		_, defined := pp.macros[macroName]
+		cond.active = defined == (name == "ifdef")
+	}
+	cond.taken = cond.active
+}
+
+func (pp *Preprocessor) elifDirective(st *fileState, args []ppToken, pos token.Position) {
+	if len(st.conditions) == 0 {
+		pp.errorf(pos, "#elif without #if")
+		return
+	}
+	cond := st.conditions[len(st.conditions)-1]
+	if cond.sawElse {
+		pp.errorf(pos, "#elif after #else")
+	}
+	if !cond.outerActive || cond.taken {
+		cond.active = false
+		return
+	}
+	cond.active = pp.evalCondition(args, pos)
+	cond.taken = cond.active
+}
+
+// macroName returns the single identifier a directive takes.
+func (pp *Preprocessor) macroName(directive string, args []ppToken, pos token.Position) (string, bool) {
+	args = trimSpace(args)
+	if len(args) == 0 {
+		pp.errorf(pos, "no macro name given in #%s directive", directive)
+		return "", false
+	}
+	if args[0].kind != ppIdent {
+		pp.errorf(pos, "macro names must be identifiers, got %s", args[0].text)
+		return "", false
+	}
+	if len(args) > 1 {
+		pp.errorf(pos, "extra tokens at end of #%s directive", directive)
+	}
+	return args[0].text, true
+}
+
+func (pp *Preprocessor) define(args []ppToken, pos token.Position) {
+	m, err := parseMacro(args)
+	if err != nil {
+		pp.errorf(pos, "%s", err)
+		return
+	}
+	if old, ok := pp.macros[m.name]; ok && !old.sameAs(m) {
+		pp.errorf(pos, "macro %s redefined", m.name)
+		return
+	}
+	pp.macros[m.name] = m
+}
+
+func (pp *Preprocessor) undef(args []ppToken, pos token.Position) {
+	if name, ok := pp.macroName("undef", args, pos); ok {
+		delete(pp.macros, name)
+	}
+}
+
+// include replaces an #include directive with the preprocessed text of the
+// file it names, between line markers.
+func (pp *Preprocessor) include(st *fileState, args []ppToken, pos token.Position) {
+	st.out.WriteString(pp.includeText(st, args, pos))
+	st.out.WriteString(fmt.Sprintf("#line %d %s\n", pos.Line+1, quote(st.file)))
+}
+
+func (pp *Preprocessor) includeText(st *fileState, args []ppToken, pos token.Position) string {
+	spec := strings.TrimSpace(joinTokens(args))
+	if !strings.HasPrefix(spec, `"`) && !strings.HasPrefix(spec, "<") {
+		// a computed include, the macros must expand to one of the forms
+		expanded, err := pp.expand(args)
+		if err != nil {
+			pp.errorf(pos, "%s", err)
+			return ""
+		}
+		spec = strings.TrimSpace(joinTokens(expanded))
+	}
+	var name string
+	system := strings.HasPrefix(spec, "<")
+	if len(spec) > 2 && (strings.HasPrefix(spec, `"`) && strings.HasSuffix(spec, `"`) || system && strings.HasSuffix(spec, ">")) {
+		name = spec[1 : len(spec)-1]
+	} else {
+		pp.errorf(pos, "#include expects \"FILENAME\" or <FILENAME>")
+		return ""
+	}
+
+	path, src, err := pp.findInclude(name, system, st.dir)
+	if err != nil {
+		pp.errorf(pos, "%s", err)
+		return ""
+	}
+	if pp.once[path] {
+		return ""
+	}
+	if pp.depth >= maxIncludeDepth {
+		pp.errorf(pos, "#include nested too deeply, including %s", name)
+		return ""
+	}
+	pp.depth++
+	defer func() { pp.depth-- }()
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("#line 1 %s\n", quote(path)))
+	pp.process(path, src, &out)
+	return out.String()
+}
+
+// findInclude looks for a "file" next to the including file first, then in
+// the include paths, where a <file> is only looked for, and last in the
+// bundled standard headers.
+func (pp *Preprocessor) findInclude(name string, system bool, dir string) (string, string, error) {
+	var candidates []string
+	if filepath.IsAbs(name) {
+		candidates = append(candidates, name)
+	} else {
+		if !system {
+			candidates = append(candidates, filepath.Join(dir, name))
+		}
+		for _, includePath := range pp.includePaths {
+			candidates = append(candidates, filepath.Join(includePath, name))
+		}
+	}
+	for _, path := range candidates {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return path, string(data), nil
+		}
+	}
+	if path, src, ok := standardHeader(name); ok {
+		return path, src, nil
+	}
+	if system {
+		return "", "", fmt.Errorf("%s: no such file or directory, the standard headers are %s", name, strings.Join(StandardHeaders(), ", "))
+	}
+	return "", "", fmt.Errorf("%s: no such file or directory", name)
+}
+
+// clean joins the lines ending with a backslash and replaces comments with a
+// space. The newlines removed are added after the line they were joined to,
+// so the lines that follow keep their numbers.
+func (pp *Preprocessor) clean(file string, src string) string {
+	var out strings.Builder
+	pending := 0 // newlines removed from the current line
+	line, column := 1, 0
+	var commentPos token.Position
+	inComment := false
+	for i := 0; i < len(src); i++ {
+		ch := src[i]
+		column++
+		if ch == '\\' && i+1 < len(src) && src[i+1] == '\n' {
+			pending++
+			line, column = line+1, 0
+			i++
+			continue
+		}
+		if ch == '\n' {
+			out.WriteByte('\n')
+			out.WriteString(strings.Repeat("\n", pending))
+			pending = 0
+			line, column = line+1, 0
+			continue
+		}
+		switch {
+		case inComment:
+			if ch == '*' && i+1 < len(src) && src[i+1] == '/' {
+				inComment = false
+				i++
+				column++
+			}
+		case ch == '/' && i+1 < len(src) && src[i+1] == '*':
+			inComment = true
+			commentPos = token.Position{File: file, Line: line, Column: column}
+			out.WriteByte(' ')
+			i++
+			column++
+		case ch == '/' && i+1 < len(src) && src[i+1] == '/':
+			out.WriteByte(' ')
+			for i+1 < len(src) && src[i+1] != '\n' {
+				// a line comment continues on the next line after a backslash
+				if src[i+1] == '\\' && i+2 < len(src) && src[i+2] == '\n' {
+					pending++
+					line++
+					i++
+				}
+				i++
+			}
+		case ch == '"' || ch == '\'':
+			end := scanQuoted(src, i)
+			out.WriteString(src[i:end])
+			column += end - i - 1
+			i = end - 1
+		default:
+			out.WriteByte(ch)
+		}
+	}
+	if inComment {
+		pp.errorf(commentPos, "unterminated block comment")
+	}
+	out.WriteString(strings.Repeat("\n", pending))
+	return out.String()
+}
+
+// errorf records a preprocessor error located at pos.
+func (pp *Preprocessor) errorf(pos token.Position, format string, a ...any) {
+	pp.errors = append(pp.errors, fmt.Errorf("%s: "+format, append([]any{pos}, a...)...))
+}
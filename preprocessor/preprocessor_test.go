@@ -0,0 +1,356 @@
+package preprocessor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mohamedirfanam/cynterpreter/eval"
+	"github.com/mohamedirfanam/cynterpreter/eval/obj"
+	"github.com/mohamedirfanam/cynterpreter/parser"
+)
+
+// normalize puts a single space between the tokens of preprocessed text
+// and drops the #line markers
+func normalize(src string) string {
+	var texts []string
+	for _, line := range strings.Split(src, "\n") {
+		if strings.HasPrefix(line, "#line ") {
+			continue
+		}
+		for _, t := range tokenize(line, 1) {
+			if t.kind != ppSpace {
+				texts = append(texts, t.text)
+			}
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+func TestMacros(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"#define N 100\nint a[N];", "int a[100];"},
+		{"#define N 1\n#define M N + N\nM;", "1 + 1;"},
+		{"#define EMPTY\nint EMPTY x;", "int x;"},
+		{"#define SQ(x) ((x) * (x))\nSQ(a + 1);", "((a + 1) * (a + 1));"},
+		{"#define MAX(a, b) ((a) > (b) ? (a) : (b))\nMAX(1, MAX(2, 3));", "((1) > (((2) > (3) ? (2) : (3))) ? (1) : (((2) > (3) ? (2) : (3))));"},
+		{"#define F(x) x\nF((1, 2));", "(1, 2);"},
+		{"#define F() 7\nF();", "7;"},
+		{"#define F(x) x\nint F = 1; F;", "int F = 1; F;"},
+		{"#define STR(x) #x\nSTR(a  +   \"b\\n\");", "\"a + \\\"b\\\\n\\\"\";"},
+		{"#define STR(x) #x\nSTR( );", "\"\";"},
+		{"#define CAT(a, b) a ## b\nCAT(foo, bar); CAT(1, 2); CAT(, x); CAT(x, );", "foobar; 12; x; x;"},
+		{"#define CAT(a, b) a ## b\n#define N 5\nCAT(N, 1);", "N1;"},
+		{"#define XCAT(a, b) CAT(a, b)\n#define CAT(a, b) a ## b\n#define N 5\nXCAT(N, 1);", "51;"},
+		{"#define ARGS(fmt, ...) printf(fmt, __VA_ARGS__)\nARGS(\"%d %d\", 1, 2);", "printf(\"%d %d\", 1, 2);"},
+		{"#define LOOP LOOP + 1\nLOOP;", "LOOP + 1;"},
+		{"#define A B\n#define B A\nA; B;", "A; B;"},
+		{"#define N 1\n#undef N\nN;", "N;"},
+		{"#define N 1\n#define N 1\nN;", "1;"},
+		{"#define MSG \"N is #N\"\n#define N 1\nMSG;", "\"N is #N\";"},
+		{"#define NEG -1\n-NEG;", "- -1;"},
+		{"#define SUM(a, b) a + b\nSUM(1,\n2);", "1 + 2;"},
+		{"#define LONG 1 + \\\n 2\nLONG;", "1 + 2;"},
+		{"#define N 1 // one\n#define M /* two */ 2\nN + M;", "1 + 2;"},
+		{"int x = __LINE__;\n\nint y = __LINE__;", "int x = 1; int y = 3;"},
+		{"char *f = __FILE__;", "char *f = \"test.c\";"},
+	}
+
+	for i, tt := range tests {
+		pp := New()
+		output := pp.Process("test.c", tt.input)
+		if len(pp.Errors()) != 0 {
+			t.Fatalf("[%d] - Preprocessor errors: %v", i, pp.Errors())
+		}
+		if normalize(output) != normalize(tt.expected) {
+			t.Errorf("[%d] - Wrong output for %q, expected %q, got %q", i, tt.input, normalize(tt.expected), normalize(output))
+		}
+	}
+}
+
+func TestConditionals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"#if 1\na;\n#else\nb;\n#endif", "a;"},
+		{"#if 0\na;\n#else\nb;\n#endif", "b;"},
+		{"#if 0\na;\n#elif 2 > 1\nb;\n#elif 1\nc;\n#else\nd;\n#endif", "b;"},
+		{"#define N 10\n#if N * 2 == 20 && !defined(M)\na;\n#endif", "a;"},
+		{"#ifdef N\na;\n#endif\n#ifndef N\nb;\n#endif", "b;"},
+		{"#define N\n#ifdef N\na;\n#endif\n#if defined N\nb;\n#endif", "a; b;"},
+		{"#if UNDEFINED == 0\na;\n#endif", "a;"},
+		{"#if 0\n#if 1\na;\n#else\nb;\n#endif\nc;\n#else\nd;\n#endif", "d;"},
+		{"#if 0\n#error not reached\n#include \"missing.h\"\n#define X 1\n#bogus\n#endif\nX;", "X;"},
+		{"#if 0x10 == 16 && 017 == 15 && 'a' == 97\na;\n#endif", "a;"},
+		{"#if (1 ? 0 : 1) || -1 < 0 && ~0 == -1\na;\n#endif", "a;"},
+		{"#if 0 && 1 / 0\na;\n#else\nb;\n#endif", "b;"},
+		{"#if 1 << 3 == 8 && 7 % 4 == 3 && (6 ^ 3) == 5\na;\n#endif", "a;"},
+		{"#define VERSION 3\n#if VERSION >= 2\nnew;\n#else\nold;\n#endif", "new;"},
+		{"#\na;", "a;"},
+	}
+
+	for i, tt := range tests {
+		pp := New()
+		output := pp.Process("test.c", tt.input)
+		if len(pp.Errors()) != 0 {
+			t.Fatalf("[%d] - Preprocessor errors: %v", i, pp.Errors())
+		}
+		if normalize(output) != normalize(tt.expected) {
+			t.Errorf("[%d] - Wrong output for %q, expected %q, got %q", i, tt.input, normalize(tt.expected), normalize(output))
+		}
+	}
+}
+
+func TestLinesKept(t *testing.T) {
+	input := "#define N 1\n/* a\n   comment */\n#if 0\nskipped;\n#endif\nint a = \\\n N;\nint b;\n"
+	output := New().Process("test.c", input)
+	lines := strings.Split(output, "\n")
+	if strings.Count(output, "\n") != strings.Count(input, "\n") {
+		t.Fatalf("Expected %d lines, got %d: %q", strings.Count(input, "\n"), strings.Count(output, "\n"), output)
+	}
+	if normalize(lines[6]) != normalize("int a = 1;") {
+		t.Errorf("Expected line 7 to be the joined declaration, got %q", lines[6])
+	}
+	if normalize(lines[8]) != normalize("int b;") {
+		t.Errorf("Expected line 9 to stay on line 9, got %q", lines[8])
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.c":         "#include \"lib/util.h\"\n#include \"lib/util.h\"\n#include <sys.h>\nint main() { return twice(N) + SYS; }\n",
+		"lib/util.h":     "#ifndef UTIL_H\n#define UTIL_H\n#include \"consts.h\"\nint twice(int v) { return v * 2; }\n#endif\n",
+		"lib/consts.h":   "#pragma once\n#define N 20\n",
+		"system/sys.h":   "#define SYS 2\n",
+		"loop.c":         "#include \"loop.c\"\n",
+		"missing.c":      "#include \"nothere.h\"\n",
+		"system_only.c":  "#include <consts.h>\n",
+		"malformed.c":    "#include consts.h\n",
+		"computed.c":     "#define HEADER \"lib/consts.h\"\n#include HEADER\nN;\n",
+		"line_markers.c": "#include \"lib/consts.h\"\nint x = 1 +;\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) (string, string) {
+		path := filepath.Join(dir, name)
+		return path, files[name]
+	}
+	includePath := filepath.Join(dir, "system")
+
+	// the included functions and macros make it to the program
+	pp := New(includePath)
+	path, src := read("main.c")
+	output := pp.Process(path, src)
+	if len(pp.Errors()) != 0 {
+		t.Fatalf("Preprocessor errors: %v", pp.Errors())
+	}
+	if strings.Count(output, "int twice") != 1 {
+		t.Errorf("Expected the guarded header once, got %q", output)
+	}
+	p := parser.NewFile(path, output)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	env := obj.NewEnv()
+	eval.Eval(program, env)
+	for _, stmt := range parser.New("main();").ParseProgram().Statements {
+		result := eval.Eval(stmt, env)
+		val, ok := result.(*obj.IntegerObject)
+		if !ok || val.Value != 42 {
+			t.Errorf("Expected main to return 42, got %s", result.String())
+		}
+	}
+
+	// errors in the parser point into the included file and back
+	pp = New()
+	path, src = read("line_markers.c")
+	p = parser.NewFile(path, pp.Process(path, src))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || !strings.HasPrefix(p.Errors()[0].Error(), path+":2:") {
+		t.Errorf("Expected a parser error at %s:2, got %v", path, p.Errors())
+	}
+
+	pp = New()
+	path, src = read("computed.c")
+	if output := pp.Process(path, src); len(pp.Errors()) != 0 || !strings.Contains(normalize(output), "20 ;") {
+		t.Errorf("Expected the computed include to define N, got %q, %v", output, pp.Errors())
+	}
+
+	errorTests := []struct {
+		file     string
+		expected string
+	}{
+		{"loop.c", "#include nested too deeply"},
+		{"missing.c", "nothere.h: no such file or directory"},
+		{"system_only.c", "consts.h: no such file or directory"},
+		{"malformed.c", "#include expects \"FILENAME\" or <FILENAME>"},
+	}
+	for i, tt := range errorTests {
+		pp := New(includePath)
+		path, src := read(tt.file)
+		pp.Process(path, src)
+		if len(pp.Errors()) == 0 {
+			t.Fatalf("[%d] - Expected preprocessor errors for %s", i, tt.file)
+		}
+		if !strings.Contains(pp.Errors()[0].Error(), tt.expected) {
+			t.Errorf("[%d] - Expected error containing %q, got %q", i, tt.expected, pp.Errors()[0])
+		}
+	}
+}
+
+func TestPreprocessorErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"#endif", "test.c:1:1: #endif without #if"},
+		{"#else", "#else without #if"},
+		{"#elif 1", "#elif without #if"},
+		{"a;\n  #if 1\nb;", "test.c:2:3: unterminated conditional directive"},
+		{"#if 1\n#else\n#else\n#endif", "test.c:3:1: #else after #else"},
+		{"#if 1\n#else\n#elif 1\n#endif", "#elif after #else"},
+		{"#if\n#endif", "#if with no expression"},
+		{"#if 1 +\n#endif", "invalid #if expression 1 +"},
+		{"#if 1 / 0\n#endif", "division by zero in #if expression"},
+		{"#if 1.5\n#endif", "1.5 is not an integer constant"},
+		{"#if defined\n#endif", "operator \"defined\" requires an identifier"},
+		{"#if defined(N\n#endif", "missing ')' after \"defined\""},
+		{"#ifdef\n#endif", "no macro name given in #ifdef directive"},
+		{"#define", "no macro name given in #define directive"},
+		{"#define 1 2", "macro names must be identifiers, got 1"},
+		{"#define F(a, a) a", "duplicate macro parameter a"},
+		{"#define F(a b", "expected ',' or ')' in macro parameter list, got b"},
+		{"#define F(a", "missing ')' in macro parameter list"},
+		{"#define F(a) #b", "'#' is not followed by a macro parameter"},
+		{"#define F(a) ## a", "'##' cannot appear at either end"},
+		{"#define N 1\n#define N 2", "test.c:2:1: macro N redefined"},
+		{"#define F(a, b) a\nF(1);", "macro F requires 2 arguments, but 1 given"},
+		{"#define F(a, b, ...) a\nF(1);", "macro F requires at least 2 arguments, but 1 given"},
+		{"#define F(a) a\nF(1, 2", "unterminated argument list invoking macro F"},
+		{"#define CAT(a, b) a ## b\nCAT(+, /);", "pasting + and / does not give a valid preprocessing token"},
+		{"#error stop here", "#error stop here"},
+		{"#foo", "invalid preprocessing directive #foo"},
+		{"#undef 3", "macro names must be identifiers, got 3"},
+		{"a;\n/* open", "test.c:2:1: unterminated block comment"},
+	}
+
+	for i, tt := range tests {
+		pp := New()
+		pp.Process("test.c", tt.input)
+		if len(pp.Errors()) == 0 {
+			t.Fatalf("[%d] - Expected preprocessor errors for %q", i, tt.input)
+		}
+		if !strings.Contains(pp.Errors()[0].Error(), tt.expected) {
+			t.Errorf("[%d] - Expected error containing %q, got %q", i, tt.expected, pp.Errors()[0])
+		}
+	}
+}
+
+func TestProgramWithMacros(t *testing.T) {
+	input := `#define SIZE 5
+#define SQUARE(x) ((x) * (x))
+#define SUM_TO(n, acc) for (int i = 1; i <= (n); i++) { acc += i; }
+#ifdef DEBUG
+int debug = 1;
+#else
+int debug = 0;
+#endif
+int main() {
+    int arr[SIZE] = {1, 2, 3, 4, 5};
+    int total = 0;
+    SUM_TO(SIZE, total)
+    return SQUARE(arr[SIZE - 1]) + total + debug;
+}
+main();`
+
+	pp := New()
+	pp.Define("DEBUG", "")
+	src := pp.Process("prog.c", input)
+	if len(pp.Errors()) != 0 {
+		t.Fatalf("Preprocessor errors: %v", pp.Errors())
+	}
+	p := parser.NewFile("prog.c", src)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	result := eval.Eval(program, obj.NewEnv())
+	val, ok := result.(*obj.IntegerObject)
+	if !ok || val.Value != 41 {
+		t.Errorf("Expected 41, got %s", result.String())
+	}
+}
+
+func TestStandardHeaders(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"#include <stdio.h>\nNULL; EOF;", "void print(...); void printf(char *format, ...); string input(...); 0; (-1);"},
+		{"#include <stdlib.h>\nRAND_MAX; EXIT_FAILURE;", "2147483647; 1;"},
+		{"#include <limits.h>\nINT_MAX; CHAR_BIT;", "2147483647; 8;"},
+		{"#include <math.h>\nM_PI;", "3.14159265358979323846;"},
+		{"#include <string.h>\n#include <ctype.h>\n#include <stddef.h>\n#include <stdbool.h>\nNULL;", "0;"},
+		{"#include \"stdio.h\"\n#include <stdio.h>\nEOF;", "void print(...); void printf(char *format, ...); string input(...); (-1);"},
+		{"#include <limits.h>\n#if INT_MAX > 32767\nbig;\n#endif", "big;"},
+	}
+
+	for i, tt := range tests {
+		pp := New()
+		output := pp.Process("test.c", tt.input)
+		if len(pp.Errors()) != 0 {
+			t.Fatalf("[%d] - Preprocessor errors: %v", i, pp.Errors())
+		}
+		if normalize(output) != normalize(tt.expected) {
+			t.Errorf("[%d] - Wrong output for %q, expected %q, got %q", i, tt.input, normalize(tt.expected), normalize(output))
+		}
+	}
+
+	// every header parses and every built-in function is declared in one
+	var all strings.Builder
+	for _, name := range StandardHeaders() {
+		pp := New()
+		src := pp.Process("test.c", "#include <"+name+">\n")
+		p := parser.NewFile("test.c", src)
+		p.ParseProgram()
+		if len(pp.Errors()) != 0 || len(p.Errors()) != 0 {
+			t.Errorf("Errors in standard header %s: %v %v", name, pp.Errors(), p.Errors())
+		}
+		all.WriteString(src)
+	}
+	for name := range eval.BuiltInFuncMap {
+		if !strings.Contains(all.String(), name+"(") {
+			t.Errorf("Built-in function %s is not declared in any standard header", name)
+		}
+	}
+
+	// a header in the include paths comes before the bundled one
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "stdio.h"), []byte("#define EOF 7\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pp := New(dir)
+	if output := pp.Process("test.c", "#include <stdio.h>\nEOF;"); normalize(output) != normalize("7;") {
+		t.Errorf("Expected the stdio.h of the include path, got %q", output)
+	}
+
+	pp = New()
+	pp.Process("test.c", "#include <conio.h>")
+	if len(pp.Errors()) == 0 || !strings.Contains(pp.Errors()[0].Error(), "test.c:1:1: conio.h: no such file or directory, the standard headers are ctype.h, limits.h") {
+		t.Errorf("Expected an unknown header error, got %v", pp.Errors())
+	}
+}
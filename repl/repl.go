@@ -9,6 +9,7 @@ import (
 	"github.com/mohamedirfanam/cynterpreter/eval"
 	"github.com/mohamedirfanam/cynterpreter/eval/obj"
 	"github.com/mohamedirfanam/cynterpreter/parser"
+	"github.com/mohamedirfanam/cynterpreter/preprocessor"
 )
 
 func REPL(in io.Reader, out io.Writer) {
@@ -18,6 +19,8 @@ func REPL(in io.Reader, out io.Writer) {
 
 	var input strings.Builder
 	var env = obj.NewEnv()
+	// macros defined in one input stay defined for the next ones
+	var pp = preprocessor.New()
 	for scanner.Scan() {
 
 		input.WriteString(scanner.Text())
@@ -25,8 +28,17 @@ func REPL(in io.Reader, out io.Writer) {
 			fmt.Fprint(out, ">>> ")
 			continue
 		}
-		var p = parser.New(input.String())
+		seenErrors := len(pp.Errors())
+		src := pp.Process("", input.String())
 		input.Reset()
+		if len(pp.Errors()) != seenErrors {
+			for _, err := range pp.Errors()[seenErrors:] {
+				fmt.Fprintf(out, "Preprocessor Error: %s\n", err.Error())
+			}
+			fmt.Fprint(out, ">>  ")
+			continue
+		}
+		var p = parser.New(src)
 
 		program := p.ParseProgram()
 
@@ -38,6 +50,10 @@ func REPL(in io.Reader, out io.Writer) {
 			continue
 		}
 
+		if len(program.Statements) == 0 {
+			fmt.Fprint(out, ">> ")
+			continue
+		}
 		result := eval.Eval(program.Statements[0], env)
 		if result.Type() != obj.NULL_OBJ {
 			fmt.Println(result.String())